@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"urlcrawler/cmd/seed"
 	"urlcrawler/internal/api"
+	"urlcrawler/internal/auth"
 	"urlcrawler/internal/config"
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/crawlhooks"
 	"urlcrawler/internal/db"
 	"urlcrawler/internal/middleware"
+	"urlcrawler/internal/notify"
+	"urlcrawler/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -29,6 +35,11 @@ func main() {
 	// Load all config values, including switching DB credentials based on APP_ENV internally
 	config.Load()
 
+	// Enable encryption of per-URL crawl credentials
+	if err := auth.SetEncryptionKey(config.Cfg.SettingsEncryptionKey); err != nil {
+		log.Println("⚠️ SETTINGS_ENCRYPTION_KEY not set. Per-URL crawl credentials are disabled.")
+	}
+
 	// Set Gin mode depending on APP_ENV
 	appEnv := os.Getenv("APP_ENV")
 	if appEnv == "production" {
@@ -49,8 +60,29 @@ func main() {
 		log.Fatalf("❌ Failed to seed admin: %v", err)
 	}
 
-	// Setup Gin router with middleware and routes
-	router := gin.Default()
+	// Set up webhooks, email notifications and alerts for finished crawls
+	crawlhooks.RegisterCrawlHooks()
+	go webhooks.RunRetries(context.Background())
+	if notify.Enabled() {
+		go notify.RunDigests(context.Background())
+	}
+
+	// Run crawls in this process, or leave them to cmd/worker processes sharing the MySQL queue
+	if config.Cfg.QueueBackend == "mysql" {
+		crawler.UseMySQLQueue()
+		go crawler.ReceiveEvents(context.Background())
+		log.Println("📮 Queueing crawls in MySQL for cmd/worker processes")
+	} else {
+		crawler.StartWorkers(config.Cfg.CrawlWorkers)
+	}
+
+	// Queue scheduled URLs as they become due
+	go crawler.RunScheduler(context.Background())
+
+	// Setup Gin router with middleware and routes; stream tokens are taken out of
+	// the URL before the request is logged
+	router := gin.New()
+	router.Use(auth.StripAccessToken(), gin.Logger(), gin.Recovery())
 	router.Use(middleware.CORSMiddleware())
 
 	apiGroup := router.Group("/api")
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/crawlhooks"
+	"urlcrawler/internal/db"
+
+	"github.com/joho/godotenv"
+)
+
+func loadEnv() {
+	// Load .env once (single env file)
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ No .env file found. Using system environment variables.")
+	} else {
+		fmt.Println("📦 Loaded environment from .env")
+	}
+}
+
+// The worker runs crawls leased from the MySQL queue, which the API server fills
+// when started with QUEUE_BACKEND=mysql. Any number of workers can run side by side.
+func main() {
+	loadEnv()
+
+	// Load all config values, including switching DB credentials based on APP_ENV internally
+	config.Load()
+
+	// Enable decryption of per-URL crawl credentials
+	if err := auth.SetEncryptionKey(config.Cfg.SettingsEncryptionKey); err != nil {
+		log.Println("⚠️ SETTINGS_ENCRYPTION_KEY not set. Per-URL crawl credentials are disabled.")
+	}
+
+	// Initialize database connection
+	if err := db.Init(); err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v", err)
+	}
+
+	// Set up webhooks, email notifications and alerts for finished crawls
+	crawlhooks.RegisterCrawlHooks()
+
+	// Run crawls until interrupted; running crawls are then handed back to the queue
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	crawler.UseMySQLQueue()
+	go crawler.ForwardEvents(ctx) // Live events reach the API server through MySQL
+	crawler.RunWorkers(ctx, config.Cfg.CrawlWorkers)
+	log.Println("👋 Worker stopped")
+}
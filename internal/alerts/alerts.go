@@ -0,0 +1,194 @@
+// Package alerts evaluates the alert rules of a URL after each crawl.
+package alerts
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/models"
+)
+
+var (
+	stateHooksMu sync.RWMutex
+	stateHooks   []func(*models.AlertRule)
+)
+
+// OnStateChange registers a function called with each rule that starts or stops firing.
+func OnStateChange(hook func(*models.AlertRule)) {
+	stateHooksMu.Lock()
+	defer stateHooksMu.Unlock()
+	stateHooks = append(stateHooks, hook)
+}
+
+func runStateHooks(rule *models.AlertRule) {
+	stateHooksMu.RLock()
+	defer stateHooksMu.RUnlock()
+	for _, hook := range stateHooks {
+		hook(rule)
+	}
+}
+
+// crawlResults holds what the rules of a URL are evaluated against. For a failed
+// crawl, whose other results are stale, only the URL and the certificate rejected
+// by this crawl, if any, are loaded.
+type crawlResults struct {
+	err    error
+	url    *models.URL
+	run    *models.CrawlRun
+	counts *models.LinkCount
+	cert   *models.Certificate // nil for plain HTTP URLs
+}
+
+// evaluation is the outcome of checking a rule against crawl results.
+type evaluation struct {
+	firing  bool
+	value   string
+	message string
+}
+
+// HandleCrawlFinished evaluates the alert rules of the crawled URL and stores their
+// state, running the state change hooks for rules that start or stop firing. It is
+// registered with crawler.OnCrawlFinished.
+func HandleCrawlFinished(f crawler.FinishedCrawl) {
+	rules, err := models.GetAlertRulesByURLID(f.URLID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load alert rules of URL ID %d: %v", f.URLID, err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	results, err := loadResults(f)
+	if err != nil {
+		log.Printf("⚠️ Failed to load crawl results of URL ID %d for alerts: %v", f.URLID, err)
+		return
+	}
+
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		eval, ok := evaluate(rule, results, now)
+		if !ok {
+			continue // The crawl produced nothing to evaluate the rule against
+		}
+
+		state := models.AlertResolved
+		if eval.firing {
+			state = models.AlertFiring
+		}
+		changed := state != rule.State
+
+		rule.State = state
+		rule.Value = eval.value
+		rule.Message = eval.message
+		rule.LastEvaluatedAt = &now
+		if changed {
+			rule.ChangedAt = &now
+		}
+		if err := models.UpdateAlertRuleEvaluation(rule); err != nil {
+			log.Printf("⚠️ Failed to save alert rule ID %d: %v", rule.ID, err)
+			continue
+		}
+
+		if changed {
+			fmt.Printf("Alert rule ID %d of URL ID %d is now %s: %s\n", rule.ID, rule.URLID, rule.State, rule.Message)
+			runStateHooks(rule)
+		}
+	}
+}
+
+func loadResults(f crawler.FinishedCrawl) (*crawlResults, error) {
+	urlObj, err := models.GetURLByID(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+	results := &crawlResults{err: f.Err, url: urlObj}
+	if results.run, err = models.GetLatestCrawlRun(f.URLID); err != nil {
+		return nil, err
+	}
+	if cert, err := models.GetCertificateByURLID(f.URLID); err == nil {
+		results.cert = cert
+	}
+
+	if f.Err != nil {
+		// A fetch failing certificate verification still records the certificate;
+		// one left from an earlier crawl says nothing about this failure
+		if results.run == nil || results.cert == nil || results.cert.CreatedAt.Before(results.run.StartedAt) {
+			results.cert = nil
+		}
+		results.run = nil
+		return results, nil
+	}
+
+	if results.counts, err = models.GetLinkCountByURLID(f.URLID); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// evaluate checks a rule against crawl results. It returns false if the results
+// say nothing about the rule, which then keeps its current state.
+func evaluate(rule *models.AlertRule, r *crawlResults, now time.Time) (evaluation, bool) {
+	if rule.Kind == models.AlertStatusError {
+		if r.err != nil {
+			return evaluation{true, string(r.url.Status), "Crawl failed: " + r.url.ErrorMessage}, true
+		}
+		return evaluation{false, string(r.url.Status), "Crawl completed"}, true
+	}
+	// A failed crawl can only be checked for an expiring or expired certificate
+	if r.err != nil && rule.Kind != models.AlertCertExpiry {
+		return evaluation{}, false
+	}
+
+	switch rule.Kind {
+	case models.AlertBrokenLinks:
+		broken := int(r.counts.Broken)
+		return evaluation{
+			firing:  broken > rule.Threshold,
+			value:   strconv.Itoa(broken),
+			message: fmt.Sprintf("%d broken links (threshold %d)", broken, rule.Threshold),
+		}, true
+
+	case models.AlertTitleChanged:
+		title := r.url.Title
+		if rule.LastEvaluatedAt == nil {
+			// First crawl since the rule was added: remember the title to compare against
+			return evaluation{false, title, fmt.Sprintf("Title is %q", title)}, true
+		}
+		if title != rule.Value {
+			return evaluation{true, title, fmt.Sprintf("Title changed from %q to %q", rule.Value, title)}, true
+		}
+		return evaluation{false, title, fmt.Sprintf("Title unchanged: %q", title)}, true
+
+	case models.AlertResponseTime:
+		if r.run == nil {
+			return evaluation{}, false
+		}
+		return evaluation{
+			firing:  r.run.TotalMs > int64(rule.Threshold),
+			value:   strconv.FormatInt(r.run.TotalMs, 10),
+			message: fmt.Sprintf("Page fetched in %dms (threshold %dms)", r.run.TotalMs, rule.Threshold),
+		}, true
+
+	case models.AlertCertExpiry:
+		if r.cert == nil {
+			return evaluation{}, false
+		}
+		days := int(r.cert.NotAfter.Sub(now).Hours() / 24)
+		message := fmt.Sprintf("Certificate expires in %d days on %s (threshold %d days)", days, r.cert.NotAfter.Format("2006-01-02"), rule.Threshold)
+		if !r.cert.NotAfter.After(now) {
+			message = "Certificate expired on " + r.cert.NotAfter.Format("2006-01-02")
+		}
+		return evaluation{
+			firing:  days < rule.Threshold,
+			value:   strconv.Itoa(days),
+			message: message,
+		}, true
+	}
+	return evaluation{}, false
+}
@@ -0,0 +1,120 @@
+package alerts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+// newExpiredTLSServer starts an HTTPS test server presenting a certificate for
+// 127.0.0.1 that expired a day ago.
+func newExpiredTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "expired.test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><title>Expired</title></html>"))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// crawl runs a crawl of a URL and its finish hooks the way a crawl worker does.
+func crawl(t *testing.T, urlID int) error {
+	t.Helper()
+	err := crawler.ProcessURL(context.Background(), urlID, crawler.Options{})
+	if err != nil {
+		models.UpdateURLStatusWithError(urlID, models.URLStatusError, err.Error())
+	}
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlID, Err: err})
+	return err
+}
+
+func TestCertExpiryFiresWhenExpiredCertificateFailsCrawl(t *testing.T) {
+	dbtest.Open(t, &models.URL{}, &models.CrawlRun{}, &models.CrawlCheckpoint{}, &models.CrawlSettings{},
+		&models.CrawlRunHAR{}, &models.Certificate{}, &models.AlertRule{})
+
+	var changes []models.AlertRule
+	stateHooks = nil
+	OnStateChange(func(r *models.AlertRule) { changes = append(changes, *r) })
+	t.Cleanup(func() { stateHooks = nil })
+
+	srv := newExpiredTLSServer(t)
+	urlObj := &models.URL{UserID: 1, URL: srv.URL, Status: models.URLStatusQueued}
+	if err := models.InsertURL(urlObj); err != nil {
+		t.Fatal(err)
+	}
+	certRule := &models.AlertRule{URLID: urlObj.ID, Kind: models.AlertCertExpiry, Threshold: 14, State: models.AlertResolved, CreatedBy: 1}
+	titleRule := &models.AlertRule{URLID: urlObj.ID, Kind: models.AlertTitleChanged, State: models.AlertResolved, CreatedBy: 1}
+	for _, r := range []*models.AlertRule{certRule, titleRule} {
+		if err := models.InsertAlertRule(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := crawl(t, urlObj.ID); err == nil {
+		t.Fatal("crawl of a server with an expired certificate succeeded")
+	}
+
+	rules, err := models.GetAlertRulesByURLID(urlObj.ID)
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("rules = %+v (%v)", rules, err)
+	}
+	if got := rules[0]; got.State != models.AlertFiring || !strings.Contains(got.Message, "Certificate expired on") {
+		t.Errorf("cert_expiry rule after the failed crawl = %+v", got)
+	}
+	if got := rules[1]; got.LastEvaluatedAt != nil {
+		t.Errorf("title_changed rule evaluated against a failed crawl: %+v", got)
+	}
+	if len(changes) != 1 || changes[0].ID != certRule.ID {
+		t.Fatalf("state changes = %+v, want the cert_expiry rule firing", changes)
+	}
+
+	// A crawl failing for another reason leaves the rule as it was: the stored
+	// certificate was recorded by the previous crawl
+	srv.Close()
+	time.Sleep(time.Millisecond) // The next run must start after the certificate was recorded
+	if err := crawl(t, urlObj.ID); err == nil {
+		t.Fatal("crawl of a stopped server succeeded")
+	}
+	rules, _ = models.GetAlertRulesByURLID(urlObj.ID)
+	if got := rules[0]; got.State != models.AlertFiring || !got.LastEvaluatedAt.Equal(*rules[0].ChangedAt) {
+		t.Errorf("cert_expiry rule after an unrelated failure = %+v", got)
+	}
+	if len(changes) != 1 {
+		t.Errorf("state changes = %+v, want only the first", changes)
+	}
+}
@@ -20,6 +20,27 @@ func SetupRoutes(router *gin.RouterGroup) {
 		authGroup.GET("/urls", handlers.GetURLsHandler)
 		authGroup.GET("/urls/:id/link-count", handlers.GetLinkCountHandler)
 		authGroup.GET("/urls/:id/broken-links", handlers.GetBrokenLinksHandler)
+		authGroup.GET("/urls/:id/mixed-content", handlers.GetMixedContentHandler)
+		authGroup.GET("/urls/:id/security-headers", handlers.GetSecurityHeadersHandler)
+		authGroup.GET("/urls/:id/certificate", handlers.GetCertificateHandler)
+		authGroup.GET("/urls/:id/runs", handlers.GetCrawlRunsHandler)
+		authGroup.GET("/urls/:id/runs/:runId", handlers.GetCrawlRunHandler)
+		authGroup.GET("/urls/:id/runs/:runId/snapshot", handlers.GetRunSnapshotHandler)
+		authGroup.GET("/urls/:id/runs/:runId/har", handlers.GetRunHARHandler)
+		authGroup.GET("/urls/:id/alerts", handlers.GetAlertRulesHandler)
+		authGroup.GET("/subscriptions", handlers.GetSubscriptionsHandler)
+		authGroup.PUT("/urls/:id/subscription", handlers.SubscribeHandler)
+		authGroup.DELETE("/urls/:id/subscription", handlers.UnsubscribeHandler)
+	}
+
+	// Live event streams, also accepting the token as a query parameter
+	streamGroup := router.Group("/")
+	streamGroup.Use(auth.StreamAuthMiddleware())
+
+	{
+		streamGroup.GET("/urls/events", handlers.GetURLEventsHandler)
+		streamGroup.GET("/urls/:id/events", handlers.GetURLEventsByIDHandler)
+		streamGroup.GET("/ws", handlers.WebSocketHandler)
 	}
 
 	// Admin-only routes
@@ -28,8 +49,22 @@ func SetupRoutes(router *gin.RouterGroup) {
 
 	{
 		adminGroup.POST("/urls", handlers.AddURLHandler)
+		adminGroup.PATCH("/urls/:id", handlers.UpdateURLHandler)
 		adminGroup.DELETE("/urls/:id", handlers.DeleteURLHandler)
 		adminGroup.POST("/urls/start", handlers.StartURLProcessingHandler)
 		adminGroup.POST("/urls/stop", handlers.StopURLProcessingHandler)
+		adminGroup.POST("/urls/pause", handlers.PauseURLProcessingHandler)
+		adminGroup.POST("/urls/resume", handlers.ResumeURLProcessingHandler)
+		adminGroup.GET("/urls/:id/settings", handlers.GetCrawlSettingsHandler)
+		adminGroup.PUT("/urls/:id/settings", handlers.UpdateCrawlSettingsHandler)
+		adminGroup.DELETE("/urls/:id/settings", handlers.DeleteCrawlSettingsHandler)
+		adminGroup.GET("/schedules", handlers.GetSchedulesHandler)
+		adminGroup.GET("/urls/:id/runs/:runId/warc", handlers.GetRunWARCHandler)
+		adminGroup.POST("/urls/:id/alerts", handlers.CreateAlertRuleHandler)
+		adminGroup.DELETE("/alerts/:id", handlers.DeleteAlertRuleHandler)
+		adminGroup.POST("/webhooks", handlers.CreateWebhookHandler)
+		adminGroup.GET("/webhooks", handlers.GetWebhooksHandler)
+		adminGroup.DELETE("/webhooks/:id", handlers.DeleteWebhookHandler)
+		adminGroup.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveriesHandler)
 	}
 }
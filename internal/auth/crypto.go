@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+var encryptionKey []byte
+
+// ErrEncryptionKeyNotSet is returned when secrets are encrypted or decrypted before a key is configured.
+var ErrEncryptionKeyNotSet = errors.New("SETTINGS_ENCRYPTION_KEY not set")
+
+// SetEncryptionKey derives the AES-256 key used to encrypt secrets at rest from the given passphrase.
+// Returns an error if the passphrase is empty.
+func SetEncryptionKey(secret string) error {
+	if secret == "" {
+		return ErrEncryptionKeyNotSet
+	}
+	key := sha256.Sum256([]byte(secret))
+	encryptionKey = key[:]
+	return nil
+}
+
+// EncryptSecret encrypts plaintext with AES-GCM and returns the nonce and ciphertext base64 encoded.
+func EncryptSecret(plaintext []byte) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret, failing if the data was tampered with or encrypted with another key.
+func DecryptSecret(encoded string) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted secret too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// newGCM returns an AES-GCM cipher using the configured key.
+func newGCM() (cipher.AEAD, error) {
+	if encryptionKey == nil {
+		return nil, ErrEncryptionKeyNotSet
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
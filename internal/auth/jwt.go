@@ -29,6 +29,11 @@ type CustomClaims struct {
 	jwt.RegisteredClaims
 }
 
+// IsAdmin reports whether the claims belong to a user with the admin role.
+func (c *CustomClaims) IsAdmin() bool {
+	return c.Role == "admin"
+}
+
 // GenerateToken creates a signed JWT token with user information and a 24-hour expiry.
 func GenerateToken(userID int, email, role, firstName, lastName string) (string, error) {
 	claims := CustomClaims{
@@ -35,6 +35,37 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// accessTokenKey is the context key under which StripAccessToken keeps the query token.
+const accessTokenKey = "access_token"
+
+// StripAccessToken removes ?access_token= from the request URL so the token is never
+// written to the access log, keeping it for StreamAuthMiddleware. It must be
+// registered before the logger, which records the query as the request arrives.
+func StripAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			c.Set(accessTokenKey, token)
+			query.Del("access_token")
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Next()
+	}
+}
+
+// StreamAuthMiddleware is AuthMiddleware for streaming endpoints. Browsers cannot set
+// headers on EventSource connections, so the token may also be passed as ?access_token=,
+// which StripAccessToken takes out of the URL beforehand.
+func StreamAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if token := c.GetString(accessTokenKey); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		auth(c)
+	}
+}
+
 // AdminOnlyMiddleware restricts access to users with admin role.
 // Expects the AuthMiddleware to have run and set the user claims in context.
 func AdminOnlyMiddleware() gin.HandlerFunc {
@@ -46,7 +77,7 @@ func AdminOnlyMiddleware() gin.HandlerFunc {
 		}
 
 		claims, ok := userClaims.(*CustomClaims)
-		if !ok || claims.Role != "admin" {
+		if !ok || !claims.IsAdmin() {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admins only"})
 			return
 		}
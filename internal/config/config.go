@@ -19,6 +19,35 @@ type Config struct {
 	CORSOrigins   string `env:"CORS_ORIGINS"    env-required:"true"`
 	AdminEmail    string `env:"ADMIN_EMAIL"     env-required:"true"`
 	AdminPassword string `env:"ADMIN_PASSWORD"  env-required:"true"`
+
+	// Crawler settings
+	CertExpiryWarnDays   int    `env:"CERT_EXPIRY_WARN_DAYS"     env-default:"30"`
+	Soft404Detection     bool   `env:"SOFT404_DETECTION"         env-default:"false"`  // Opt-in: fetch internal links to detect "not found" pages served with 200
+	RetryMaxAttempts     int    `env:"CRAWL_RETRY_ATTEMPTS"      env-default:"3"`      // Attempts per request for transient failures, including the first
+	RetryBaseDelayMs     int    `env:"CRAWL_RETRY_BASE_DELAY_MS" env-default:"500"`    // Backoff before the first retry, doubled on each retry
+	RetryMaxDelayMs      int    `env:"CRAWL_RETRY_MAX_DELAY_MS"  env-default:"8000"`   // Upper bound for a single backoff
+	WARCDir              string `env:"WARC_DIR"                  env-default:"warc"`   // Directory for WARC archives of crawl runs; must be shared with cmd/worker processes
+	SchedulerTickSeconds int    `env:"SCHEDULER_TICK_SECONDS"    env-default:"30"`     // How often the scheduler looks for due URLs
+	CrawlWorkers         int    `env:"CRAWL_WORKERS"             env-default:"4"`      // Number of URLs crawled in parallel by each process
+	QueueBackend         string `env:"QUEUE_BACKEND"             env-default:"memory"` // "memory" crawls in the server; "mysql" leaves crawls to cmd/worker processes
+	JobLeaseSeconds      int    `env:"JOB_LEASE_SECONDS"         env-default:"60"`     // How long a worker may go without a heartbeat before its job is given to another
+	JobHeartbeatSeconds  int    `env:"JOB_HEARTBEAT_SECONDS"     env-default:"10"`     // How often workers renew their leases and check for stop and pause requests
+
+	// Webhook settings
+	WebhookMaxAttempts    int `env:"WEBHOOK_MAX_ATTEMPTS"    env-default:"5"`    // Delivery attempts per event, including the first
+	WebhookBaseDelayMs    int `env:"WEBHOOK_BASE_DELAY_MS"   env-default:"2000"` // Backoff before the first retry, doubled on each retry
+	WebhookTimeoutSeconds int `env:"WEBHOOK_TIMEOUT_SECONDS" env-default:"10"`   // Timeout of a single delivery attempt
+
+	// Email notification settings; notifications are disabled without an SMTP host
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     int    `env:"SMTP_PORT"     env-default:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+	SMTPFrom     string `env:"SMTP_FROM"     env-default:"urlcrawler@localhost"`
+	DigestHour   int    `env:"DIGEST_HOUR"   env-default:"8"` // Hour of the day daily digests are sent; weekly digests go out on Mondays
+
+	// Passphrase for encrypting per-URL crawl credentials at rest; credentials are disabled without it
+	SettingsEncryptionKey string `env:"SETTINGS_ENCRYPTION_KEY"`
 }
 
 
@@ -0,0 +1,40 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// collectAnchors returns the fragment targets of a document: element ids and <a name> values.
+func collectAnchors(doc *goquery.Document) map[string]bool {
+	anchors := map[string]bool{}
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("id")
+		anchors[id] = true
+	})
+	doc.Find("a[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		anchors[name] = true
+	})
+	return anchors
+}
+
+// isDanglingAnchor reports whether the fragment of target does not match any anchor
+// in the target document. Links without a fragment, the implicit "#top", and targets
+// that could not be fetched or parsed are never reported.
+func isDanglingAnchor(ctx context.Context, pages *pageCache, target *url.URL) bool {
+	fragment := target.Fragment
+	if fragment == "" || strings.EqualFold(fragment, "top") {
+		return false
+	}
+
+	page := pages.get(ctx, target)
+	if page == nil || page.StatusCode != http.StatusOK {
+		return false
+	}
+	return !page.Anchors[fragment]
+}
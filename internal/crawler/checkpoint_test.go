@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+func TestFailedResumeKeepsCheckpoint(t *testing.T) {
+	dbtest.Open(t, &models.URL{}, &models.CrawlRun{}, &models.CrawlCheckpoint{},
+		&models.CrawlSettings{}, &models.CrawlRunHAR{}, &models.PageSnapshot{})
+
+	u := &models.URL{UserID: 1, URL: "https://example.com", Status: models.URLStatusPaused}
+	if err := models.InsertURL(u); err != nil {
+		t.Fatalf("failed to insert URL: %v", err)
+	}
+	run, err := models.StartCrawlRun(u.ID)
+	if err != nil {
+		t.Fatalf("failed to start run: %v", err)
+	}
+	err = models.SaveCrawlCheckpoint(&models.CrawlCheckpoint{
+		URLID:   u.ID,
+		RunID:   run.ID,
+		Pending: []models.FrontierItem{{Href: "https://example.com/next", ResourceType: models.ResourceLink}},
+	})
+	if err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+
+	// The paused run's page snapshot is missing, so the resume fails before checking any link
+	if err := ProcessURL(context.Background(), u.ID, Options{Resume: true}); err == nil {
+		t.Fatal("resume without a page snapshot succeeded")
+	}
+
+	checkpoint, err := models.GetCrawlCheckpoint(u.ID)
+	if err != nil || checkpoint == nil {
+		t.Fatalf("checkpoint lost after a failed resume: %v", err)
+	}
+	if len(checkpoint.Pending) != 1 {
+		t.Errorf("checkpoint has %d pending items, want 1", len(checkpoint.Pending))
+	}
+}
+
+func TestSchedulerSkipsPausedURLs(t *testing.T) {
+	dbtest.Open(t, &models.URL{})
+
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	u := &models.URL{UserID: 1, URL: "https://example.com", Status: models.URLStatusPaused, Schedule: "1h", NextRunAt: &due}
+	if err := models.InsertURL(u); err != nil {
+		t.Fatalf("failed to insert URL: %v", err)
+	}
+
+	startDueCrawls(now)
+
+	if _, queued := GetTask(u.ID); queued {
+		t.Error("scheduler queued a crawl of a paused URL")
+	}
+	stored, _ := models.GetURLByID(u.ID)
+	if stored.Status != models.URLStatusPaused {
+		t.Errorf("status = %q, want %q", stored.Status, models.URLStatusPaused)
+	}
+	if stored.NextRunAt == nil || !stored.NextRunAt.After(now) {
+		t.Errorf("next run %v not moved past %v", stored.NextRunAt, now)
+	}
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+
+	"urlcrawler/internal/models"
+)
+
+// requestTimeout bounds every request made by the crawler so a stalled server cannot hang a crawl.
+const requestTimeout = 30 * time.Second
+
+// newCrawlClient builds the HTTP client used for one crawl of pageURL. Each crawl gets
+// its own cookie jar; configured credentials and cookies only go to the page's host.
+// Recorders, if any, see every request as it is sent, with its credential headers
+// and login query values redacted.
+func newCrawlClient(pageURL *url.URL, creds *models.CrawlCredentials, recorders ...exchangeRecorder) *http.Client {
+	jar, _ := cookiejar.New(nil) // Never fails without options
+	client := &http.Client{
+		Timeout:   requestTimeout,
+		Jar:       jar,
+		Transport: http.DefaultTransport,
+	}
+	if len(recorders) > 0 {
+		client.Transport = &recordingTransport{
+			base:        client.Transport,
+			recorders:   recorders,
+			redact:      redactedHeaders(creds),
+			redactQuery: redactedQueryFields(creds),
+		}
+	}
+	if creds == nil {
+		return client
+	}
+
+	// Host-only cookies: the jar never sends them to other hosts. Without a path the
+	// jar would scope them to the page's directory rather than the whole site.
+	var cookies []*http.Cookie
+	for _, c := range creds.Cookies {
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value, Path: path})
+	}
+	jar.SetCookies(pageURL, cookies)
+
+	client.Transport = &credentialsTransport{
+		base:  client.Transport,
+		host:  pageURL.Host,
+		creds: creds,
+	}
+	return client
+}
+
+// credentialsTransport adds basic auth, bearer token and custom headers to
+// requests for a single host, leaving requests to other hosts untouched.
+type credentialsTransport struct {
+	base  http.RoundTripper
+	host  string
+	creds *models.CrawlCredentials
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, value := range t.creds.Headers {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case t.creds.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+t.creds.BearerToken)
+	case t.creds.BasicUser != "":
+		req.SetBasicAuth(t.creds.BasicUser, t.creds.BasicPass)
+	}
+	return t.base.RoundTrip(req)
+}
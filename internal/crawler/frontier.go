@@ -0,0 +1,205 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/events"
+	"urlcrawler/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// buildFrontier lists the links of a page followed by its sub-resources, in the order they are checked.
+func buildFrontier(doc *goquery.Document, pageURL *url.URL) []models.FrontierItem {
+	var frontier []models.FrontierItem
+	doc.Find("a").Each(func(i int, s *goquery.Selection) {
+		href, exists := s.Attr("href")
+		if !exists || strings.TrimSpace(href) == "" {
+			return
+		}
+		frontier = append(frontier, models.FrontierItem{
+			Href:         resolveHref(pageURL, href).String(),
+			ResourceType: models.ResourceLink,
+		})
+	})
+	for _, res := range extractResources(doc) {
+		frontier = append(frontier, models.FrontierItem{
+			Href:         resolveHref(pageURL, res.Href).String(),
+			ResourceType: res.Type,
+		})
+	}
+	return frontier
+}
+
+// checkFrontier checks and stores the given links and sub-resources of a page.
+// checked is the number of items already checked before a pause. If the crawl is
+// paused, the unchecked items are saved as a checkpoint for resuming later.
+func checkFrontier(ctx context.Context, client *http.Client, urlObj *models.URL, run *models.CrawlRun, pageURL *url.URL, page *fetchedPage, frontier []models.FrontierItem, checked int) error {
+	// Fragments and (opt-in) soft 404s are validated against the target document
+	pages := newPageCache(client)
+	pages.add(pageURL, page)
+	soft404 := newSoft404Detector(pages)
+	headUnsupported := map[string]bool{} // Hosts found to mishandle HEAD during this crawl
+
+	progress := events.Progress{Phase: events.PhaseLinks, LinksFound: checked + len(frontier), LinksChecked: checked}
+	if checked > 0 {
+		// Resuming: count the broken links found before the pause
+		if counts, err := models.GetLinkCountByURLID(urlObj.ID); err == nil {
+			progress.BrokenLinks = int(counts.Broken)
+		}
+	}
+	reportProgress(urlObj.ID, progress)
+
+	for i, item := range frontier {
+		if ctx.Err() != nil {
+			return interruptCrawl(ctx, urlObj, run, page, frontier[i:], checked+i)
+		}
+
+		link := checkFrontierItem(ctx, client, pageURL, pages, soft404, headUnsupported, item)
+		// A check cut short by the pause is repeated on resume rather than stored
+		if ctx.Err() != nil {
+			return interruptCrawl(ctx, urlObj, run, page, frontier[i:], checked+i)
+		}
+
+		link.URLID = urlObj.ID
+		models.InsertLink(link.Link)
+		if item.ResourceType != models.ResourceLink {
+			run.PageWeight += link.ContentLength
+		}
+
+		progress.LinksChecked++
+		if link.IsBroken {
+			progress.BrokenLinks++
+		}
+		reportProgress(urlObj.ID, progress)
+	}
+	return nil
+}
+
+// frontierLink is a checked frontier item together with the size reported for it.
+type frontierLink struct {
+	models.Link
+	ContentLength int64
+}
+
+// checkFrontierItem checks a single link or sub-resource. Only http(s) references
+// are checked over the network; others are validated by syntax.
+func checkFrontierItem(ctx context.Context, client *http.Client, pageURL *url.URL, pages *pageCache, soft404 *soft404Detector, headUnsupported map[string]bool, item models.FrontierItem) frontierLink {
+	absURL, err := url.Parse(item.Href)
+	if err != nil {
+		absURL = &url.URL{Path: item.Href} // Same fallback as resolveHref
+	}
+
+	link := models.Link{
+		Href:         item.Href,
+		ResourceType: item.ResourceType,
+		Kind:         classifyLink(absURL),
+		IsInternal:   absURL.Host == pageURL.Host, // Check if link is internal (same host) or external
+	}
+	if link.Kind != models.LinkKindHTTP {
+		// Non-http sub-resources such as inline data: URIs need no check
+		if item.ResourceType == models.ResourceLink {
+			link.Issue = checkNonHTTPLink(absURL, link.Kind)
+		}
+		return frontierLink{Link: link}
+	}
+
+	result := checkLink(ctx, client, headUnsupported, item.Href)
+	link.StatusCode = result.StatusCode
+	link.CheckMethod = result.Method
+	link.Attempts = result.Attempts
+	link.IsFlaky = result.IsFlaky
+	link.IsBroken = result.IsBroken
+
+	if item.ResourceType == models.ResourceLink && !result.IsBroken && link.IsInternal {
+		switch {
+		case config.Cfg.Soft404Detection && soft404.isSoft404(ctx, absURL):
+			link.Issue = models.LinkIssueSoft404
+		case isDanglingAnchor(ctx, pages, absURL):
+			link.Issue = models.LinkIssueDanglingAnchor
+		}
+	}
+
+	return frontierLink{Link: link, ContentLength: max(result.ContentLength, 0)}
+}
+
+// interruptCrawl ends a crawl whose context was cancelled while checking the frontier.
+// A pause saves the remaining items as the URL's checkpoint; a stop discards them.
+func interruptCrawl(ctx context.Context, urlObj *models.URL, run *models.CrawlRun, page *fetchedPage, pending []models.FrontierItem, checked int) error {
+	if !errors.Is(context.Cause(ctx), models.ErrCrawlPaused) {
+		return ctx.Err()
+	}
+
+	err := models.SaveCrawlCheckpoint(&models.CrawlCheckpoint{
+		URLID:        urlObj.ID,
+		RunID:        run.ID,
+		StatusCode:   page.StatusCode,
+		ETag:         urlObj.ETag,
+		LastModified: urlObj.LastModified,
+		Pending:      pending,
+		Checked:      checked,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	fmt.Printf("Paused URL ID %d with %d links left to check\n", urlObj.ID, len(pending))
+	return fmt.Errorf("%w after checking %d links", models.ErrCrawlPaused, checked)
+}
+
+// resumeFromCheckpoint finishes a paused crawl: the page is taken from the paused
+// run's snapshot instead of being fetched again, and only the unchecked items are checked.
+func resumeFromCheckpoint(ctx context.Context, client *http.Client, urlObj *models.URL, run *models.CrawlRun, pageURL *url.URL, checkpoint *models.CrawlCheckpoint) error {
+	bodyBytes, err := models.GetPageSnapshotHTML(urlObj.ID, checkpoint.RunID)
+	if err != nil {
+		return fmt.Errorf("failed to load page snapshot: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	urlObj.HTMLVersion = detectHTMLVersion(string(bodyBytes))
+	urlObj.Title = strings.TrimSpace(doc.Find("title").Text())
+	urlObj.ETag = checkpoint.ETag
+	urlObj.LastModified = checkpoint.LastModified
+
+	// 8-9. Check the links and sub-resources left when the crawl was paused. The
+	// checkpoint is used up from here on; pausing again saves a new one.
+	if err := models.DeleteCrawlCheckpoint(urlObj.ID); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	page := summarisePage(checkpoint.StatusCode, doc, len(bodyBytes))
+	if err := checkFrontier(ctx, client, urlObj, run, pageURL, page, checkpoint.Pending, checkpoint.Checked); err != nil {
+		return err
+	}
+
+	// 10. Detect mixed content and insecure links on HTTPS pages
+	if err := models.DeleteMixedContentIssuesByURLID(urlObj.ID); err != nil {
+		return fmt.Errorf("failed to delete old mixed content issues: %w", err)
+	}
+	mixedContent := detectMixedContent(doc, pageURL)
+	for _, issue := range mixedContent {
+		issue.URLID = urlObj.ID
+		models.InsertMixedContentIssue(issue)
+	}
+	urlObj.MixedContentCount = len(mixedContent)
+
+	// 11. Update URL with status, HTML version and the validators for the next crawl
+	urlObj.Status = models.URLStatusDone
+	urlObj.UpdatedAt = time.Now()
+	if err := models.UpdateURLCrawlResult(urlObj); err != nil {
+		return fmt.Errorf("failed to update URL status: %w", err)
+	}
+
+	fmt.Printf("Finished resumed crawl of URL ID %d\n", urlObj.ID)
+	return nil
+}
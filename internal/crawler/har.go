@@ -0,0 +1,278 @@
+package crawler
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/models"
+)
+
+// HAR 1.2 structures, limited to the fields the crawler can fill in.
+// See http://www.softwareishard.com/blog/har-12-spec/
+type harLog struct {
+	Log harLogBody `json:"log"`
+}
+
+type harLogBody struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"` // Custom field: transport error of a request that got no response
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// harTimings are in milliseconds; -1 marks a phase that did not happen, e.g. on a reused connection.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"` // Includes SSL, as the spec requires
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// harRecorder collects the exchanges of a crawl as HAR entries.
+// Bodies are not included; request bodies are replaced because login forms carry passwords,
+// and the values of credential headers and cookies are redacted.
+type harRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+func (h *harRecorder) record(ex *exchange) {
+	entry := harEntry{
+		StartedDateTime: ex.Started.Format("2006-01-02T15:04:05.000Z07:00"),
+		Request:         harRequestOf(ex),
+		Timings:         harTimingsOf(ex.Timings),
+	}
+	entry.Time = entry.Timings.total()
+
+	if ex.Response == nil {
+		// The spec requires a response; status 0 is what browsers export for failed requests
+		entry.Response = harResponse{Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1}
+		if ex.Err != nil {
+			entry.Error = ex.Err.Error()
+		}
+	} else {
+		entry.Response = harResponseOf(ex)
+	}
+
+	h.mu.Lock()
+	h.entries = append(h.entries, entry)
+	h.mu.Unlock()
+}
+
+// JSON returns the HAR document of all recorded exchanges, ordered by start time.
+func (h *harRecorder) JSON() ([]byte, error) {
+	h.mu.Lock()
+	entries := append([]harEntry{}, h.entries...)
+	h.mu.Unlock()
+
+	// Exchanges are recorded when their response body is closed, not when they start
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime < entries[j].StartedDateTime
+	})
+
+	return json.Marshal(harLog{Log: harLogBody{
+		Version: "1.2",
+		Creator: harCreator{Name: "urlcrawler", Version: "1.0"},
+		Entries: entries,
+	}})
+}
+
+// load starts the recorder with the entries already stored for a run, so that
+// the HAR of a resumed run also covers the requests made before the pause.
+func (h *harRecorder) load(urlID, runID int) {
+	data, err := models.GetCrawlRunHAR(urlID, runID)
+	if err != nil {
+		return
+	}
+	var stored harLog
+	if err := json.Unmarshal(data, &stored); err == nil {
+		h.entries = stored.Log.Entries
+	}
+}
+
+// saveHAR stores the HAR of a crawl run. Failures are only logged so they never fail the crawl.
+func saveHAR(urlID, runID int, h *harRecorder) {
+	data, err := h.JSON()
+	if err == nil {
+		err = models.SaveCrawlRunHAR(urlID, runID, data)
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to store HAR of run %d: %v", runID, err)
+	}
+}
+
+func harRequestOf(ex *exchange) harRequest {
+	req := ex.Request
+	header := ex.RequestHeader.Clone()
+	header.Set("Host", req.URL.Host)
+
+	r := harRequest{
+		Method:      req.Method,
+		URL:         ex.RequestURL.String(),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(header),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(ex.RequestBody),
+	}
+	if ex.Response != nil {
+		r.HTTPVersion = ex.Response.Proto
+	}
+	for _, c := range req.Cookies() {
+		r.Cookies = append(r.Cookies, harNameValue{Name: c.Name, Value: "[redacted]"})
+	}
+	for name, values := range ex.RequestURL.Query() {
+		for _, value := range values {
+			r.QueryString = append(r.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if len(ex.RequestBody) > 0 {
+		r.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: "[redacted]"}
+	}
+	return r
+}
+
+func harResponseOf(ex *exchange) harResponse {
+	resp := ex.Response
+	r := harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(redactHeader(resp.Header, []string{"Set-Cookie"})),
+		Content: harContent{
+			Size:     len(ex.ResponseBody),
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(ex.ResponseBody),
+	}
+	for _, c := range resp.Cookies() {
+		r.Cookies = append(r.Cookies, harNameValue{Name: c.Name, Value: "[redacted]"})
+	}
+	return r
+}
+
+// harHeaders flattens a header into HAR name/value pairs in a stable order.
+func harHeaders(header http.Header) []harNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []harNameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	return pairs
+}
+
+// harTimingsOf converts the traced phases of a request into HAR timings.
+func harTimingsOf(t *pageTimings) harTimings {
+	timings := harTimings{
+		Blocked: -1,
+		DNS:     harMillis(t.dnsStart, t.dnsDone),
+		Connect: -1,
+		Send:    harMillis(t.gotConn, t.wroteRequest),
+		Wait:    harMillis(t.wroteRequest, t.firstByte),
+		Receive: harMillis(t.firstByte, t.done),
+		SSL:     harMillis(t.tlsStart, t.tlsDone),
+	}
+	if !t.tlsDone.IsZero() {
+		timings.Connect = harMillis(t.connectStart, t.tlsDone)
+	} else {
+		timings.Connect = harMillis(t.connectStart, t.connectDone)
+	}
+
+	// Whatever remains before the connection was ready was spent waiting for it
+	if !t.gotConn.IsZero() {
+		blocked := harMillis(t.start, t.gotConn) - max(timings.DNS, 0) - max(timings.Connect, 0)
+		timings.Blocked = math.Round(max(blocked, 0)*1000) / 1000
+	}
+	return timings
+}
+
+// total is the entry time: the sum of all phases, SSL being part of connect.
+func (t harTimings) total() float64 {
+	var total float64
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
+
+// harMillis returns the milliseconds between two timestamps, or -1 if either was not recorded.
+func harMillis(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	return float64(to.Sub(from).Microseconds()) / 1000
+}
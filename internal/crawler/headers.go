@@ -0,0 +1,193 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"urlcrawler/internal/models"
+)
+
+// minHSTSMaxAge is the shortest HSTS max-age (180 days) not flagged as a warning.
+const minHSTSMaxAge = 15552000
+
+// auditSecurityHeaders runs the security header and cookie checks against the
+// response of the crawled page.
+func auditSecurityHeaders(resp *http.Response) []models.SecurityCheck {
+	isHTTPS := resp.Request != nil && resp.Request.URL.Scheme == "https"
+	h := resp.Header
+	csp := h.Get("Content-Security-Policy")
+
+	checks := []models.SecurityCheck{
+		checkHSTS(h.Get("Strict-Transport-Security"), isHTTPS),
+		checkCSP(csp),
+		checkFrameOptions(h.Get("X-Frame-Options"), csp),
+		checkContentTypeOptions(h.Get("X-Content-Type-Options")),
+		checkReferrerPolicy(h.Get("Referrer-Policy")),
+		checkPermissionsPolicy(h.Get("Permissions-Policy")),
+	}
+
+	for _, cookie := range resp.Cookies() {
+		checks = append(checks, checkCookie(cookie, isHTTPS))
+	}
+
+	return checks
+}
+
+// redactSetCookie returns a copy of header with the values of Set-Cookie headers
+// redacted, keeping the cookie names and attributes the cookie checks report on.
+// Authenticated crawls receive live session cookies, which must not be stored.
+func redactSetCookie(header http.Header) http.Header {
+	header = header.Clone()
+	for i, line := range header.Values("Set-Cookie") {
+		pair, attrs, _ := strings.Cut(line, ";")
+		name, _, _ := strings.Cut(pair, "=")
+		redacted := strings.TrimSpace(name) + "=[redacted]"
+		if attrs != "" {
+			redacted += ";" + attrs
+		}
+		header["Set-Cookie"][i] = redacted
+	}
+	return header
+}
+
+// gradeSecurityChecks returns the worst result among the checks.
+func gradeSecurityChecks(checks []models.SecurityCheck) models.SecurityResult {
+	grade := models.SecurityPass
+	for _, c := range checks {
+		switch c.Result {
+		case models.SecurityFail:
+			return models.SecurityFail
+		case models.SecurityWarn:
+			grade = models.SecurityWarn
+		}
+	}
+	return grade
+}
+
+func checkHSTS(value string, isHTTPS bool) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "Strict-Transport-Security"}
+	switch {
+	case !isHTTPS:
+		check.Result, check.Detail = models.SecurityFail, "page is not served over HTTPS"
+	case value == "":
+		check.Result, check.Detail = models.SecurityFail, "header missing"
+	default:
+		maxAge := -1
+		for _, directive := range strings.Split(value, ";") {
+			name, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if strings.EqualFold(name, "max-age") {
+				maxAge, _ = strconv.Atoi(strings.Trim(val, `"`))
+			}
+		}
+		if maxAge < minHSTSMaxAge {
+			check.Result, check.Detail = models.SecurityWarn, fmt.Sprintf("max-age %d is below %d", maxAge, minHSTSMaxAge)
+		} else {
+			check.Result, check.Detail = models.SecurityPass, value
+		}
+	}
+	return check
+}
+
+func checkCSP(value string) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "Content-Security-Policy"}
+	lower := strings.ToLower(value)
+	switch {
+	case value == "":
+		check.Result, check.Detail = models.SecurityFail, "header missing"
+	case strings.Contains(lower, "'unsafe-inline'") || strings.Contains(lower, "'unsafe-eval'"):
+		check.Result, check.Detail = models.SecurityWarn, "policy allows 'unsafe-inline' or 'unsafe-eval'"
+	default:
+		check.Result, check.Detail = models.SecurityPass, value
+	}
+	return check
+}
+
+// checkFrameOptions accepts either X-Frame-Options or a CSP frame-ancestors directive.
+func checkFrameOptions(value, csp string) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "X-Frame-Options"}
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	switch {
+	case strings.Contains(strings.ToLower(csp), "frame-ancestors"):
+		check.Result, check.Detail = models.SecurityPass, "framing restricted by CSP frame-ancestors"
+	case upper == "DENY" || upper == "SAMEORIGIN":
+		check.Result, check.Detail = models.SecurityPass, value
+	case value != "":
+		check.Result, check.Detail = models.SecurityWarn, fmt.Sprintf("unsupported value %q", value)
+	default:
+		check.Result, check.Detail = models.SecurityFail, "neither X-Frame-Options nor frame-ancestors set"
+	}
+	return check
+}
+
+func checkContentTypeOptions(value string) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "X-Content-Type-Options"}
+	if strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		check.Result, check.Detail = models.SecurityPass, value
+	} else {
+		check.Result, check.Detail = models.SecurityFail, "expected nosniff"
+	}
+	return check
+}
+
+func checkReferrerPolicy(value string) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "Referrer-Policy"}
+	// The last recognised policy in a comma separated list is the one applied
+	policies := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+	switch policy {
+	case "":
+		check.Result, check.Detail = models.SecurityWarn, "header missing"
+	case "unsafe-url", "no-referrer-when-downgrade":
+		check.Result, check.Detail = models.SecurityWarn, fmt.Sprintf("%s leaks full URLs to other origins", policy)
+	default:
+		check.Result, check.Detail = models.SecurityPass, value
+	}
+	return check
+}
+
+func checkPermissionsPolicy(value string) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "Permissions-Policy"}
+	if value == "" {
+		check.Result, check.Detail = models.SecurityWarn, "header missing"
+	} else {
+		check.Result, check.Detail = models.SecurityPass, value
+	}
+	return check
+}
+
+// checkCookie verifies the Secure, HttpOnly and SameSite flags of a cookie set by the page.
+func checkCookie(cookie *http.Cookie, isHTTPS bool) models.SecurityCheck {
+	check := models.SecurityCheck{Name: "Set-Cookie: " + cookie.Name, Result: models.SecurityPass}
+	var problems []string
+
+	if !cookie.Secure && isHTTPS {
+		check.Result = models.SecurityFail
+		problems = append(problems, "missing Secure")
+	}
+	if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+		check.Result = models.SecurityFail
+		problems = append(problems, "SameSite=None without Secure")
+	}
+	if !cookie.HttpOnly {
+		if check.Result == models.SecurityPass {
+			check.Result = models.SecurityWarn
+		}
+		problems = append(problems, "missing HttpOnly")
+	}
+	// An absent SameSite attribute parses as the zero value rather than SameSiteDefaultMode
+	if cookie.SameSite == 0 || cookie.SameSite == http.SameSiteDefaultMode {
+		if check.Result == models.SecurityPass {
+			check.Result = models.SecurityWarn
+		}
+		problems = append(problems, "missing SameSite")
+	}
+
+	if len(problems) == 0 {
+		check.Detail = "Secure, HttpOnly and SameSite set"
+	} else {
+		check.Detail = strings.Join(problems, ", ")
+	}
+	return check
+}
@@ -0,0 +1,31 @@
+package crawler
+
+import "sync"
+
+// FinishedCrawl is the outcome of a crawl that completed or failed. Crawls
+// stopped or paused by an admin do not finish and run no hooks.
+type FinishedCrawl struct {
+	URLID int
+	Err   error // nil if the crawl completed
+}
+
+var (
+	finishHooksMu sync.RWMutex
+	finishHooks   []func(FinishedCrawl)
+)
+
+// OnCrawlFinished registers a function called by the worker after each finished crawl.
+// Hooks run on the worker goroutine, so slow work must be handed off.
+func OnCrawlFinished(hook func(FinishedCrawl)) {
+	finishHooksMu.Lock()
+	defer finishHooksMu.Unlock()
+	finishHooks = append(finishHooks, hook)
+}
+
+func runFinishHooks(f FinishedCrawl) {
+	finishHooksMu.RLock()
+	defer finishHooksMu.RUnlock()
+	for _, hook := range finishHooks {
+		hook(f)
+	}
+}
@@ -0,0 +1,220 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/models"
+)
+
+const (
+	jobPollInterval     = 2 * time.Second
+	jobMaxLeaseAttempts = 3 // Leases of a job whose worker keeps dying before it is failed
+)
+
+var (
+	errLeaseLost      = errors.New("job lease lost to another worker")
+	errWorkerShutdown = errors.New("worker shutting down")
+)
+
+// workerID identifies this process in the MySQL queue; empty while the in-memory queue is used.
+var workerID string
+
+// UseMySQLQueue makes this process share the crawl_jobs table with other processes
+// instead of keeping crawls in an in-memory queue: Enqueue inserts jobs, workers
+// lease them, and stopping or pausing a URL signals whichever process runs it.
+// It must be called before crawls are queued or workers started.
+func UseMySQLQueue() {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	workerID = fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// UsesMySQLQueue reports whether UseMySQLQueue was called.
+func UsesMySQLQueue() bool {
+	return workerID != ""
+}
+
+// RunWorkers runs n workers leasing crawls from the MySQL queue until ctx is cancelled.
+// Crawls still running then are interrupted and put back in the queue for other
+// workers; RunWorkers returns once they have stopped.
+func RunWorkers(ctx context.Context, n int) {
+	n = max(n, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job := leaseJob(ctx)
+				if job == nil {
+					return
+				}
+				stop := context.AfterFunc(ctx, func() { job.task.cancel(errWorkerShutdown) })
+				runJob(job)
+				stop()
+			}
+		}()
+	}
+
+	log.Printf("👷 Worker %s started %d crawl workers on the MySQL queue", workerID, n)
+	wg.Wait()
+}
+
+// enqueueMySQL queues a crawl in the MySQL queue unless the URL already has a job.
+func enqueueMySQL(job Job) bool {
+	options, err := json.Marshal(job.Options)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode crawl options of URL ID %d: %v", job.URLID, err)
+		return false
+	}
+
+	queued, err := models.InsertCrawlJob(&models.CrawlJob{
+		URLID:    job.URLID,
+		UserID:   job.UserID,
+		Priority: int(job.Priority),
+		Options:  string(options),
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to queue crawl of URL ID %d: %v", job.URLID, err)
+		return false
+	}
+	if queued {
+		models.UpdateURLStatus(job.URLID, models.URLStatusQueued)
+	}
+	return queued
+}
+
+// leaseJob blocks until a job is leased from the MySQL queue and returns it ready
+// to run, or returns nil once ctx is cancelled.
+func leaseJob(ctx context.Context) *Job {
+	lease := jobLease()
+	for {
+		reapJobs()
+
+		row, err := models.LeaseCrawlJob(workerID, lease)
+		if err != nil {
+			log.Printf("⚠️ Failed to lease a crawl job: %v", err)
+		}
+		if row != nil {
+			return newLeasedJob(row)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+func newLeasedJob(row *models.CrawlJob) *Job {
+	job := &Job{
+		URLID:    row.URLID,
+		UserID:   row.UserID,
+		Priority: Priority(row.Priority),
+		QueuedAt: row.QueuedAt,
+		jobID:    row.ID,
+	}
+	if err := json.Unmarshal([]byte(row.Options), &job.Options); err != nil {
+		log.Printf("⚠️ Ignoring invalid crawl options of URL ID %d: %v", row.URLID, err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	job.ctx = ctx
+	job.task = &task{cancel: cancel}
+	activeTasks.Store(job.URLID, job.task)
+	go heartbeat(job)
+	return job
+}
+
+// heartbeat renews the lease of a running job until it ends, and cancels the
+// crawl when the API server asks for it to be stopped or paused, or when the
+// lease was lost to another worker.
+func heartbeat(job *Job) {
+	lease := jobLease()
+	ticker := time.NewTicker(jobHeartbeat())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-job.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		control, ok, err := models.RenewCrawlJobLease(job.jobID, workerID, lease)
+		switch {
+		case err != nil:
+			log.Printf("⚠️ Failed to renew lease of URL ID %d: %v", job.URLID, err)
+		case !ok:
+			job.task.cancel(errLeaseLost)
+		case control == models.CrawlJobStop:
+			job.task.cancel(nil)
+		case control == models.CrawlJobPause:
+			job.task.cancel(models.ErrCrawlPaused)
+		}
+	}
+}
+
+// finishLeasedJob removes a job from the MySQL queue once its crawl has ended. A job
+// interrupted by shutdown goes back to the queue; one whose lease was lost now
+// belongs to another worker and is left alone.
+func finishLeasedJob(job *Job) {
+	var err error
+	switch context.Cause(job.ctx) {
+	case errLeaseLost:
+		return
+	case errWorkerShutdown:
+		err = models.ReleaseCrawlJob(job.jobID, workerID)
+		if err == nil {
+			models.UpdateURLStatus(job.URLID, models.URLStatusQueued)
+		}
+	default:
+		err = models.DeleteCrawlJob(job.jobID, workerID)
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to update crawl job of URL ID %d: %v", job.URLID, err)
+	}
+}
+
+// reapJobs fails the jobs whose crawls keep losing their worker.
+func reapJobs() {
+	failed, err := models.ReapCrawlJobs(jobMaxLeaseAttempts)
+	if err != nil {
+		log.Printf("⚠️ Failed to reap expired crawl jobs: %v", err)
+		return
+	}
+	for _, row := range failed {
+		err := fmt.Errorf("crawl abandoned after its worker was lost %d times", row.Attempts)
+		models.UpdateURLStatusWithError(row.URLID, models.URLStatusError, err.Error())
+		runFinishHooks(FinishedCrawl{URLID: row.URLID, Err: err})
+	}
+}
+
+// controlJob asks the process running the job of a URL to stop or pause it.
+func controlJob(urlID int, control models.CrawlJobControl) bool {
+	ok, err := models.ControlCrawlJob(urlID, control)
+	if err != nil {
+		log.Printf("⚠️ Failed to %s crawl job of URL ID %d: %v", control, urlID, err)
+		return false
+	}
+	return ok
+}
+
+func jobLease() time.Duration {
+	return time.Duration(max(config.Cfg.JobLeaseSeconds, 10)) * time.Second
+}
+
+func jobHeartbeat() time.Duration {
+	return min(time.Duration(max(config.Cfg.JobHeartbeatSeconds, 1))*time.Second, jobLease()/2)
+}
@@ -0,0 +1,117 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// headRejectedStatuses are HEAD responses that say nothing about the resource
+// itself and are confirmed with a GET before being trusted.
+var headRejectedStatuses = map[int]bool{
+	http.StatusForbidden:        true,
+	http.StatusMethodNotAllowed: true,
+	http.StatusNotImplemented:   true,
+}
+
+// resolveHref resolves an href found on the page against the page URL.
+// Unparseable values are kept as a bare path so they still get recorded.
+func resolveHref(pageURL *url.URL, href string) *url.URL {
+	absURL, err := pageURL.Parse(href)
+	if err != nil {
+		absURL = &url.URL{Path: href} // fallback
+	}
+	return absURL
+}
+
+// linkResult is the outcome of checking a single link or sub-resource.
+type linkResult struct {
+	StatusCode    int // 0 means no response was received
+	IsBroken      bool
+	ContentLength int64  // -1 when the server did not report a length
+	Method        string // HTTP method that produced StatusCode
+	Attempts      int    // Requests sent for Method, including retries
+	IsFlaky       bool   // True if the link only succeeded after retries
+}
+
+// checkLink requests linkURL and reports its status code and whether the link
+// should be considered broken. HEAD is tried first; rejected HEAD requests, and
+// requests that fail outright, are checked with a GET that avoids downloading the body.
+// Hosts whose GET answer differs from the rejected HEAD are added to headUnsupported,
+// the hosts of this crawl whose later checks go straight to GET.
+func checkLink(ctx context.Context, client *http.Client, headUnsupported map[string]bool, linkURL string) linkResult {
+	host := ""
+	if u, err := url.Parse(linkURL); err == nil {
+		host = u.Host
+	}
+
+	headStatus := 0
+	if !headUnsupported[host] {
+		resp, attempts, err := doCheckRequest(ctx, client, http.MethodHead, linkURL, false)
+		if err == nil {
+			resp.Body.Close()
+			if !headRejectedStatuses[resp.StatusCode] {
+				return newLinkResult(resp, http.MethodHead, attempts)
+			}
+			headStatus = resp.StatusCode
+		}
+	}
+
+	resp, attempts, err := doCheckRequest(ctx, client, http.MethodGet, linkURL, true)
+	if err == nil && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// Empty resources cannot satisfy a range; ask again for the whole (empty) body
+		resp.Body.Close()
+		resp, attempts, err = doCheckRequest(ctx, client, http.MethodGet, linkURL, false)
+	}
+	if err != nil {
+		return linkResult{IsBroken: true, ContentLength: -1, Method: http.MethodGet, Attempts: attempts}
+	}
+	resp.Body.Close() // Closed unread: only the status and headers are needed
+	// A resource that also refuses GET is just forbidden; the host handles HEAD fine
+	if headStatus != 0 && resp.StatusCode != headStatus {
+		headUnsupported[host] = true
+	}
+	return newLinkResult(resp, http.MethodGet, attempts)
+}
+
+// doCheckRequest sends a link check request, optionally asking for the first byte only.
+// Transient failures are retried with backoff; the number of attempts is returned.
+func doCheckRequest(ctx context.Context, client *http.Client, method, linkURL string, ranged bool) (*http.Response, int, error) {
+	return doWithRetry(ctx, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, linkURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if ranged {
+			req.Header.Set("Range", "bytes=0-0")
+		}
+		return client.Do(req)
+	})
+}
+
+// newLinkResult builds a linkResult from a check response. For partial responses
+// the full size is taken from Content-Range.
+func newLinkResult(resp *http.Response, method string, attempts int) linkResult {
+	result := linkResult{
+		StatusCode:    resp.StatusCode,
+		IsBroken:      resp.StatusCode >= 400,
+		ContentLength: resp.ContentLength,
+		Method:        method,
+		Attempts:      attempts,
+		IsFlaky:       attempts > 1 && resp.StatusCode < 400,
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		result.ContentLength = -1
+		// Content-Range: bytes 0-0/12345
+		if _, total, found := strings.Cut(resp.Header.Get("Content-Range"), "/"); found {
+			if size, err := strconv.ParseInt(total, 10, 64); err == nil {
+				result.ContentLength = size
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,146 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"urlcrawler/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// performLogin runs a login recipe with the crawl client so the session cookies
+// it obtains end up in the client's cookie jar. Any failure wraps models.ErrLoginFailed.
+func performLogin(ctx context.Context, client *http.Client, recipe *models.LoginRecipe) error {
+	loginURL, err := url.Parse(recipe.LoginURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid login URL: %v", models.ErrLoginFailed, err)
+	}
+
+	// 1. Load the login page to get the form and its CSRF token
+	doc, err := fetchDocument(ctx, client, http.MethodGet, loginURL.String(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load login page: %v", models.ErrLoginFailed, err)
+	}
+
+	selector := recipe.FormSelector
+	if selector == "" {
+		selector = `form:has(input[type="password"])`
+	}
+	form := doc.Find(selector).First()
+	if form.Length() == 0 {
+		return fmt.Errorf("%w: login form %q not found", models.ErrLoginFailed, selector)
+	}
+
+	// 2. Fill the form, keeping hidden fields such as CSRF tokens as served
+	values := url.Values{}
+	form.Find("input[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		inputType := strings.ToLower(s.AttrOr("type", "text"))
+		if inputType == "submit" || inputType == "button" || inputType == "image" {
+			return
+		}
+		if (inputType == "checkbox" || inputType == "radio") && !s.Is("[checked]") {
+			return
+		}
+		values.Set(name, s.AttrOr("value", ""))
+	})
+	values.Set(recipe.UsernameField, recipe.Username)
+	values.Set(recipe.PasswordField, recipe.Password)
+	for name, value := range recipe.ExtraFields {
+		values.Set(name, value)
+	}
+
+	headers := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	if token, ok := doc.Find(`meta[name="csrf-token"]`).Attr("content"); ok {
+		headers.Set("X-CSRF-Token", token)
+	}
+
+	// 3. Submit the form to its action, defaulting to the login page itself
+	action := resolveHref(loginURL, form.AttrOr("action", ""))
+	method := http.MethodPost
+	if strings.EqualFold(form.AttrOr("method", ""), http.MethodGet) {
+		method = http.MethodGet
+		action.RawQuery = values.Encode()
+	}
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(values.Encode())
+	}
+	result, err := fetchDocument(ctx, client, method, action.String(), body, headers)
+	if err != nil {
+		// The error names the URL, which holds the credentials of a GET form
+		shown := redactURL(action, []string{recipe.UsernameField, recipe.PasswordField}).String()
+		msg := strings.ReplaceAll(err.Error(), action.String(), shown)
+		return fmt.Errorf("%w: login request failed: %s", models.ErrLoginFailed, msg)
+	}
+
+	// 4. Check the success condition
+	return checkLoginSuccess(client, loginURL, result, recipe)
+}
+
+// checkLoginSuccess evaluates the recipe's success condition against the page
+// returned after submitting the form. Without an explicit condition, the login
+// succeeded if that page no longer shows a password field.
+func checkLoginSuccess(client *http.Client, loginURL *url.URL, doc *goquery.Document, recipe *models.LoginRecipe) error {
+	text := doc.Text()
+
+	if recipe.FailureText != "" && strings.Contains(text, recipe.FailureText) {
+		return fmt.Errorf("%w: page contains failure text %q", models.ErrLoginFailed, recipe.FailureText)
+	}
+	if recipe.SuccessText != "" && !strings.Contains(text, recipe.SuccessText) {
+		return fmt.Errorf("%w: page does not contain success text %q", models.ErrLoginFailed, recipe.SuccessText)
+	}
+	if recipe.SuccessCookie != "" {
+		found := false
+		for _, cookie := range client.Jar.Cookies(loginURL) {
+			if cookie.Name == recipe.SuccessCookie {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: cookie %q was not set", models.ErrLoginFailed, recipe.SuccessCookie)
+		}
+	}
+
+	noCondition := recipe.FailureText == "" && recipe.SuccessText == "" && recipe.SuccessCookie == ""
+	if noCondition && doc.Find(`input[type="password"]`).Length() > 0 {
+		return fmt.Errorf("%w: still on a page with a password field", models.ErrLoginFailed)
+	}
+
+	return nil
+}
+
+// fetchDocument sends a request and parses the HTML response, treating 4xx and 5xx as errors.
+func fetchDocument(ctx context.Context, client *http.Client, method, target string, body io.Reader, headers http.Header) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %d from %s", resp.StatusCode, target)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(bodyBytes))
+}
@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"urlcrawler/internal/models"
+)
+
+func TestGETLoginIsNotRecorded(t *testing.T) {
+	const password = "s3cret-pass"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<form method="get" action="/session"><input name="user"><input type="password" name="pass"></form>`)
+	})
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pass") != password {
+			http.Error(w, "wrong password", http.StatusForbidden)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+	mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<p>Welcome</p>`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	pageURL, _ := url.Parse(srv.URL + "/home")
+	creds := &models.CrawlCredentials{Login: &models.LoginRecipe{
+		LoginURL:      srv.URL + "/login",
+		UsernameField: "user",
+		PasswordField: "pass",
+		Username:      "alice",
+		Password:      password,
+		SuccessCookie: "session",
+	}}
+
+	har := &harRecorder{}
+	warcPath := filepath.Join(t.TempDir(), "run.warc.gz")
+	warc, err := newWARCWriter(warcPath)
+	if err != nil {
+		t.Fatalf("failed to create WARC file: %v", err)
+	}
+	client := newCrawlClient(pageURL, creds, har, warc)
+
+	if err := performLogin(context.Background(), client, creds.Login); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	warc.Close()
+
+	harJSON, err := har.JSON()
+	if err != nil {
+		t.Fatalf("failed to encode HAR: %v", err)
+	}
+	file, err := os.ReadFile(warcPath)
+	if err != nil {
+		t.Fatalf("failed to read WARC file: %v", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("failed to open WARC file: %v", err)
+	}
+	warcData, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to read WARC file: %v", err)
+	}
+
+	for name, data := range map[string]string{"HAR": string(harJSON), "WARC": string(warcData)} {
+		if strings.Contains(data, password) || strings.Contains(data, "alice") {
+			t.Errorf("%s contains the login credentials", name)
+		}
+		if !strings.Contains(data, "/session?") {
+			t.Errorf("%s does not record the login request", name)
+		}
+	}
+}
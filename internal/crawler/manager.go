@@ -3,13 +3,21 @@ package crawler
 import (
 	"context"
 	"sync"
+
+	"urlcrawler/internal/models"
 )
 
-var activeTasks = sync.Map{} // map[int]context.CancelFunc
+// task is the entry of a queued or running crawl in the task list. It is stored
+// by pointer so a finished crawl only removes its own entry, not a newer one.
+type task struct {
+	cancel context.CancelCauseFunc
+}
+
+var activeTasks = sync.Map{} // map[int]*task
 
 // Add URL to task list
 func RegisterTask(urlID int, cancel context.CancelFunc) {
-	activeTasks.Store(urlID, cancel)
+	activeTasks.Store(urlID, &task{cancel: func(error) { cancel() }})
 }
 
 // Remove URL from task list
@@ -23,17 +31,33 @@ func GetTask(urlID int) (context.CancelFunc, bool) {
 	if !ok {
 		return nil, false
 	}
-	cancelFunc, ok := val.(context.CancelFunc)
-	return cancelFunc, ok
+	t := val.(*task)
+	return func() { t.cancel(nil) }, true
 }
 
-// Cancel a running URL process
+// Cancel a queued or running URL process; with the MySQL queue, the process running it is signalled
 func CancelTask(urlID int) bool {
+	if UsesMySQLQueue() {
+		return controlJob(urlID, models.CrawlJobStop)
+	}
+	val, ok := activeTasks.LoadAndDelete(urlID)
+	if ok {
+		val.(*task).cancel(nil)
+		return true
+	}
+	return false
+}
+
+// Pause a queued or running URL process; a running crawl checkpoints its progress before stopping.
+// The task stays registered until its worker has saved the checkpoint, so the URL cannot be
+// resumed before then.
+func PauseTask(urlID int) bool {
+	if UsesMySQLQueue() {
+		return controlJob(urlID, models.CrawlJobPause)
+	}
 	val, ok := activeTasks.Load(urlID)
 	if ok {
-		cancel := val.(context.CancelFunc)
-		cancel()
-		activeTasks.Delete(urlID)
+		val.(*task).cancel(models.ErrCrawlPaused)
 		return true
 	}
 	return false
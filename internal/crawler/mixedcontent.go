@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"urlcrawler/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// passiveResourceTypes are the sub-resource types browsers treat as optionally-blockable
+// (passive) mixed content. Every other sub-resource type is active mixed content.
+var passiveResourceTypes = map[models.ResourceType]bool{
+	models.ResourceVideo:   true,
+	models.ResourceAudio:   true,
+	models.ResourceFavicon: true,
+}
+
+// detectMixedContent reports references on an HTTPS page that are loaded or submitted
+// over plain HTTP. Pages not served over HTTPS never have mixed content.
+func detectMixedContent(doc *goquery.Document, pageURL *url.URL) []models.MixedContentIssue {
+	if pageURL.Scheme != "https" {
+		return nil
+	}
+
+	var issues []models.MixedContentIssue
+	isInsecure := func(ref string) (*url.URL, bool) {
+		absURL := resolveHref(pageURL, ref)
+		return absURL, absURL.Scheme == "http"
+	}
+
+	// Sub-resources loaded over HTTP
+	for _, res := range extractResources(doc) {
+		absURL, insecure := isInsecure(res.Href)
+		if !insecure {
+			continue
+		}
+		kind := models.MixedContentActive
+		if passiveResourceTypes[res.Type] {
+			kind = models.MixedContentPassive
+		}
+		issues = append(issues, models.MixedContentIssue{
+			Href:    absURL.String(),
+			Element: string(res.Type),
+			Kind:    kind,
+		})
+	}
+
+	// Images are not checked as resources but still count as passive mixed content
+	doc.Find("img[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		if absURL, insecure := isInsecure(strings.TrimSpace(src)); insecure {
+			issues = append(issues, models.MixedContentIssue{
+				Href:    absURL.String(),
+				Element: "img",
+				Kind:    models.MixedContentPassive,
+			})
+		}
+	})
+
+	// Forms submitting over HTTP
+	doc.Find("form[action]").Each(func(i int, s *goquery.Selection) {
+		action, _ := s.Attr("action")
+		if absURL, insecure := isInsecure(strings.TrimSpace(action)); insecure {
+			issues = append(issues, models.MixedContentIssue{
+				Href:    absURL.String(),
+				Element: "form",
+				Kind:    models.MixedContentForm,
+			})
+		}
+	})
+
+	// Links downgrading to HTTP on the same host
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		absURL, insecure := isInsecure(strings.TrimSpace(href))
+		if insecure && absURL.Hostname() == pageURL.Hostname() {
+			issues = append(issues, models.MixedContentIssue{
+				Href:    absURL.String(),
+				Element: "link",
+				Kind:    models.MixedContentDowngrade,
+			})
+		}
+	})
+
+	return issues
+}
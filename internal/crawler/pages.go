@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// fetchedPage summarises an internal page downloaded while checking links.
+type fetchedPage struct {
+	StatusCode int
+	Title      string
+	Heading    string // Text of the first <h1>
+	BodySize   int
+	Anchors    map[string]bool
+}
+
+// pageCache downloads internal pages on demand so that each linked page is
+// fetched at most once per crawl, however many checks need its content.
+type pageCache struct {
+	client *http.Client
+	pages  map[string]*fetchedPage // Keyed by URL without fragment; nil if the page could not be fetched or parsed
+}
+
+func newPageCache(client *http.Client) *pageCache {
+	return &pageCache{client: client, pages: map[string]*fetchedPage{}}
+}
+
+// withoutFragment returns u as a string with its fragment removed.
+func withoutFragment(u *url.URL) string {
+	stripped := *u
+	stripped.Fragment = ""
+	stripped.RawFragment = ""
+	return stripped.String()
+}
+
+// summarisePage builds a fetchedPage from a parsed document.
+func summarisePage(statusCode int, doc *goquery.Document, bodySize int) *fetchedPage {
+	return &fetchedPage{
+		StatusCode: statusCode,
+		Title:      strings.TrimSpace(doc.Find("title").First().Text()),
+		Heading:    strings.TrimSpace(doc.Find("h1").First().Text()),
+		BodySize:   bodySize,
+		Anchors:    collectAnchors(doc),
+	}
+}
+
+// add stores an already downloaded page in the cache.
+func (c *pageCache) add(pageURL *url.URL, page *fetchedPage) {
+	c.pages[withoutFragment(pageURL)] = page
+}
+
+// get returns the page at target, downloading it on first use. Non-HTML responses
+// and failed requests yield nil.
+func (c *pageCache) get(ctx context.Context, target *url.URL) *fetchedPage {
+	key := withoutFragment(target)
+	if page, seen := c.pages[key]; seen {
+		return page
+	}
+
+	page := fetchPage(ctx, c.client, key)
+	c.pages[key] = page
+	return page
+}
+
+// fetchPage downloads and parses an HTML page, returning nil on any failure.
+func fetchPage(ctx context.Context, client *http.Client, pageURL string) *fetchedPage {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return summarisePage(resp.StatusCode, doc, len(body))
+}
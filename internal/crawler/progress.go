@@ -0,0 +1,8 @@
+package crawler
+
+import "urlcrawler/internal/events"
+
+// reportProgress publishes the progress of a running crawl to live subscribers.
+func reportProgress(urlID int, progress events.Progress) {
+	events.Publish(events.Event{Type: events.TypeProgress, URLID: urlID, Progress: &progress})
+}
@@ -0,0 +1,199 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/models"
+)
+
+// Priority orders queued crawls; higher priorities are always started first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"low", "normal", "high", "urgent"} // Indexed by Priority
+
+// ParsePriority converts a priority name to a Priority; an empty name is normal priority.
+func ParsePriority(name string) (Priority, error) {
+	if name == "" {
+		return PriorityNormal, nil
+	}
+	for p, n := range priorityNames {
+		if strings.EqualFold(name, n) {
+			return Priority(p), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown priority %q", name)
+}
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	if p < PriorityLow || p > PriorityUrgent {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// Job is a crawl waiting in the queue.
+type Job struct {
+	URLID    int
+	UserID   int // Submitting user; jobs of the same priority are shared fairly between users
+	Priority Priority
+	Options  Options
+	QueuedAt time.Time
+
+	ctx   context.Context
+	task  *task
+	jobID int // Row in the MySQL queue, if used
+}
+
+// userQueue holds the pending jobs of one priority level, one FIFO per user,
+// and serves users in round-robin order.
+type userQueue struct {
+	jobs  map[int][]*Job
+	users []int // Users with pending jobs, next to be served first
+}
+
+func (q *userQueue) push(job *Job) {
+	if len(q.jobs[job.UserID]) == 0 {
+		q.users = append(q.users, job.UserID)
+	}
+	q.jobs[job.UserID] = append(q.jobs[job.UserID], job)
+}
+
+// pop takes the oldest job of the next user in turn and moves that user to the back.
+func (q *userQueue) pop() *Job {
+	user := q.users[0]
+	q.users = q.users[1:]
+
+	job := q.jobs[user][0]
+	q.jobs[user] = q.jobs[user][1:]
+	if len(q.jobs[user]) > 0 {
+		q.users = append(q.users, user)
+	} else {
+		delete(q.jobs, user)
+	}
+	return job
+}
+
+// jobQueue is the crawl queue shared by the worker pool.
+type jobQueue struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	levels map[Priority]*userQueue
+}
+
+var queue = newJobQueue()
+
+func newJobQueue() *jobQueue {
+	q := &jobQueue{levels: map[Priority]*userQueue{}}
+	q.ready = sync.NewCond(&q.mu)
+	for p := PriorityLow; p <= PriorityUrgent; p++ {
+		q.levels[p] = &userQueue{jobs: map[int][]*Job{}}
+	}
+	return q
+}
+
+func (q *jobQueue) push(job *Job) {
+	q.mu.Lock()
+	q.levels[job.Priority].push(job)
+	q.mu.Unlock()
+	q.ready.Signal()
+}
+
+// pop blocks until a job is available and returns the next one to run.
+func (q *jobQueue) pop() *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for p := PriorityUrgent; p >= PriorityLow; p-- {
+			if len(q.levels[p].users) > 0 {
+				return q.levels[p].pop()
+			}
+		}
+		q.ready.Wait()
+	}
+}
+
+// Enqueue queues a crawl of a URL unless the URL is already queued or being crawled.
+// Returns false without queueing anything in that case.
+func Enqueue(job Job) bool {
+	if job.Priority < PriorityLow || job.Priority > PriorityUrgent {
+		job.Priority = PriorityNormal
+	}
+	if UsesMySQLQueue() {
+		return enqueueMySQL(job)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t := &task{cancel: cancel}
+	if _, exists := activeTasks.LoadOrStore(job.URLID, t); exists {
+		cancel(nil)
+		return false
+	}
+
+	job.ctx = ctx
+	job.task = t
+	job.QueuedAt = time.Now()
+	models.UpdateURLStatus(job.URLID, models.URLStatusQueued)
+	queue.push(&job)
+	return true
+}
+
+// StartWorkers starts n workers that run crawls from the in-memory queue.
+func StartWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		go worker()
+	}
+	log.Printf("👷 Started %d crawl workers", n)
+}
+
+func worker() {
+	for {
+		job := queue.pop()
+		runJob(job)
+	}
+}
+
+// runJob crawls the URL of a job and records the outcome on the URL.
+func runJob(job *Job) {
+	defer func() {
+		job.task.cancel(nil)
+		activeTasks.CompareAndDelete(job.URLID, job.task)
+		if job.jobID != 0 {
+			finishLeasedJob(job)
+		}
+	}()
+
+	// Stopped or paused while still waiting in the queue
+	if job.ctx.Err() != nil {
+		return
+	}
+
+	models.UpdateURLStatus(job.URLID, models.URLStatusProcessing)
+	err := ProcessURL(job.ctx, job.URLID, job.Options)
+	switch {
+	case job.ctx.Err() != nil:
+		// Stopped or paused by an admin, who already set the URL's status, or handed
+		// over to another worker
+	case err != nil:
+		models.UpdateURLStatusWithError(job.URLID, models.URLStatusError, err.Error())
+		runFinishHooks(FinishedCrawl{URLID: job.URLID, Err: err})
+	default:
+		models.UpdateURLStatus(job.URLID, models.URLStatusDone)
+		runFinishHooks(FinishedCrawl{URLID: job.URLID})
+	}
+}
@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/models"
+)
+
+// credentialHeaders are the standard request headers that carry credentials.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// redactedHeaders returns the request headers whose values must never be recorded
+// for a crawl: the standard credential headers plus every custom header that
+// credentialsTransport adds from the URL's credentials.
+func redactedHeaders(creds *models.CrawlCredentials) []string {
+	names := append([]string{}, credentialHeaders...)
+	if creds != nil {
+		for name := range creds.Headers {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// redactedQueryFields returns the query parameters whose values must never be
+// recorded for a crawl: the login username and password, which a login form
+// submitted with GET sends in the URL.
+func redactedQueryFields(creds *models.CrawlCredentials) []string {
+	if creds == nil || creds.Login == nil {
+		return nil
+	}
+	return []string{creds.Login.UsernameField, creds.Login.PasswordField}
+}
+
+// redactURL returns u, or a copy of it with the values of the named query parameters replaced.
+func redactURL(u *url.URL, names []string) *url.URL {
+	query := u.Query()
+	redacted := false
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			query.Set(name, "[redacted]")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u
+	}
+	copied := *u
+	copied.RawQuery = query.Encode()
+	return &copied
+}
+
+// redactHeader returns a copy of header with the values of the named headers replaced.
+func redactHeader(header http.Header, names []string) http.Header {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for _, name := range names {
+		if header.Get(name) != "" {
+			header.Set(name, "[redacted]")
+		}
+	}
+	return header
+}
+
+// exchange is a request made by the crawl client together with its outcome.
+type exchange struct {
+	Request       *http.Request
+	RequestURL    *url.URL       // URL of Request with login query values redacted, for recording
+	RequestHeader http.Header    // Headers of Request with credential values redacted, for recording
+	RequestBody   []byte         // Only login forms send a body; recorders must not store it as it holds the password
+	Response      *http.Response // nil if the request failed
+	ResponseBody  []byte         // Bytes actually read by the crawler
+	Complete      bool           // True if the response body was read to the end
+	Err           error
+	Started       time.Time
+	Timings       *pageTimings // Connection phases of the request; done is set when the exchange completes
+}
+
+// exchangeRecorder receives every completed exchange of a crawl.
+type exchangeRecorder interface {
+	record(ex *exchange)
+}
+
+// recordingTransport captures the requests sent through it and hands them to the
+// recorders once the response body is closed, so the recorded body is what the crawler read.
+type recordingTransport struct {
+	base        http.RoundTripper
+	recorders   []exchangeRecorder
+	redact      []string // Request headers whose values are redacted in RequestHeader
+	redactQuery []string // Query parameters whose values are redacted in RequestURL and the Referer header
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, timings := withTimings(req.Context())
+	req = req.WithContext(ctx) // Trace hooks compose with any trace already on the context
+	ex := &exchange{
+		Request:       req,
+		RequestURL:    redactURL(req.URL, t.redactQuery),
+		RequestHeader: redactHeader(req.Header, t.redact),
+		Started:       timings.start,
+		Timings:       timings,
+	}
+	// Redirects from a login submitted with GET carry its URL as the referrer
+	if referer, err := url.Parse(ex.RequestHeader.Get("Referer")); err == nil && referer.RawQuery != "" {
+		ex.RequestHeader.Set("Referer", redactURL(referer, t.redactQuery).String())
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			ex.RequestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		ex.Err = err
+		timings.done = time.Now()
+		t.finish(ex)
+		return nil, err
+	}
+
+	ex.Response = resp
+	resp.Body = &recordingBody{body: resp.Body, ex: ex, done: t.finish}
+	return resp, nil
+}
+
+// finish passes a completed exchange to every recorder.
+func (t *recordingTransport) finish(ex *exchange) {
+	for _, r := range t.recorders {
+		r.record(ex)
+	}
+}
+
+// recordingBody keeps a copy of the bytes read from a response body and
+// completes the exchange when the body is closed.
+type recordingBody struct {
+	body io.ReadCloser
+	ex   *exchange
+	buf  bytes.Buffer
+	once sync.Once
+	done func(*exchange)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.ex.Complete = true
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(func() {
+		b.ex.ResponseBody = b.buf.Bytes()
+		b.ex.Timings.done = time.Now()
+		b.done(b.ex)
+	})
+	return err
+}
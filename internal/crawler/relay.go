@@ -0,0 +1,153 @@
+package crawler
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/events"
+	"urlcrawler/internal/models"
+)
+
+const (
+	eventRelayInterval = time.Second     // How often progress is relayed and relayed events are read
+	eventRetention     = time.Minute     // How long relayed events are kept for the API server
+	eventSettleTime    = 5 * time.Second // How long a relayed event may commit after one with a higher ID
+	eventBatchSize     = 500
+)
+
+// relay holds the latest progress of each crawl not relayed yet. Progress is
+// relayed at most once per eventRelayInterval; status changes are relayed at once.
+// Events are stored under mu so they reach the API server in the order published.
+var relay = struct {
+	mu       sync.Mutex
+	progress map[int]events.Event
+}{progress: map[int]events.Event{}}
+
+// ForwardEvents makes the events of the crawls run by this worker process reach
+// the subscribers of the API server through MySQL, until ctx is cancelled.
+func ForwardEvents(ctx context.Context) {
+	events.Forward(relayEvent)
+
+	ticker := time.NewTicker(eventRelayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushProgress()
+			return
+		case <-ticker.C:
+			flushProgress()
+		}
+	}
+}
+
+func relayEvent(e events.Event) {
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+
+	if e.Type == events.TypeProgress {
+		relay.progress[e.URLID] = e
+		return
+	}
+	delete(relay.progress, e.URLID) // Superseded by the status change
+	storeEvent(e)
+}
+
+// flushProgress relays the latest progress of every crawl that reported some since the last flush.
+func flushProgress() {
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+
+	for urlID, e := range relay.progress {
+		storeEvent(e)
+		delete(relay.progress, urlID)
+	}
+}
+
+func storeEvent(e events.Event) {
+	if err := models.InsertCrawlEvent(e); err != nil {
+		log.Printf("⚠️ Failed to relay %s event of URL ID %d: %v", e.Type, e.URLID, err)
+	}
+}
+
+// ReceiveEvents publishes the events relayed by worker processes to the
+// subscribers of this process until ctx is cancelled, and removes relayed events
+// once they are older than eventRetention.
+func ReceiveEvents(ctx context.Context) {
+	lastID, err := models.GetLastCrawlEventID()
+	if err != nil {
+		log.Printf("⚠️ Failed to load the last relayed event: %v", err)
+	}
+	r := newEventReceiver(lastID)
+
+	ticker := time.NewTicker(eventRelayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.receive(now)
+			if err := models.DeleteCrawlEventsBefore(now.Add(-eventRetention)); err != nil {
+				log.Printf("⚠️ Failed to delete relayed events: %v", err)
+			}
+		}
+	}
+}
+
+// eventReceiver reads the relayed events in ID order. Workers insert concurrently,
+// so an event may commit after one with a higher ID was read; events are read
+// again until eventSettleTime after they were first seen, and published once.
+type eventReceiver struct {
+	horizon int               // Every event up to this ID has been published or will never commit
+	seen    map[int]time.Time // Events after horizon already published, with when they were first read
+}
+
+func newEventReceiver(lastID int) *eventReceiver {
+	return &eventReceiver{horizon: lastID, seen: map[int]time.Time{}}
+}
+
+// receive publishes the relayed events not published yet and moves the horizon
+// past the events that have settled.
+func (r *eventReceiver) receive(now time.Time) {
+	afterID := r.horizon
+	for {
+		rows, err := models.GetCrawlEventsAfter(afterID, eventBatchSize)
+		if err != nil {
+			log.Printf("⚠️ Failed to load relayed events: %v", err)
+			return
+		}
+		for i := range rows {
+			row := &rows[i]
+			afterID = row.ID
+			if _, ok := r.seen[row.ID]; ok {
+				continue
+			}
+			r.seen[row.ID] = now
+			if e, err := row.Decode(); err == nil {
+				events.Publish(e)
+			}
+		}
+		if len(rows) < eventBatchSize {
+			break
+		}
+	}
+
+	ids := make([]int, 0, len(r.seen))
+	for id := range r.seen {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if now.Sub(r.seen[id]) < eventSettleTime {
+			break
+		}
+		r.horizon = id
+		delete(r.seen, id)
+	}
+}
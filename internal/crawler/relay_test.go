@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"urlcrawler/internal/db"
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/events"
+	"urlcrawler/internal/models"
+)
+
+func TestRelayEvents(t *testing.T) {
+	dbtest.Open(t, &models.CrawlEvent{})
+	sub := events.Subscribe(1)
+	defer sub.Close()
+
+	progress := func(checked int) events.Event {
+		return events.Event{Type: events.TypeProgress, URLID: 1, Progress: &events.Progress{Phase: events.PhaseLinks, LinksChecked: checked}}
+	}
+	status := func(s models.URLStatus) events.Event {
+		return events.Event{Type: events.TypeStatus, URLID: 1, Status: string(s)}
+	}
+
+	// Worker side: only the latest progress is relayed, and progress not relayed
+	// yet is dropped once the crawl's status changes
+	relayEvent(status(models.URLStatusProcessing))
+	relayEvent(progress(1))
+	relayEvent(progress(2))
+	flushProgress()
+	relayEvent(progress(3))
+	relayEvent(status(models.URLStatusDone))
+	flushProgress()
+
+	// API server side
+	r := newEventReceiver(0)
+	now := time.Now()
+	r.receive(now)
+	r.receive(now.Add(time.Second)) // Events already published are not published again
+
+	want := []string{"status processing", "progress 2", "status done"}
+	for _, w := range want {
+		select {
+		case e := <-sub.C:
+			got := fmt.Sprintf("%s %s", e.Type, e.Status)
+			if e.Progress != nil {
+				got = fmt.Sprintf("%s %d", e.Type, e.Progress.LinksChecked)
+			}
+			if got != w {
+				t.Errorf("received %q, want %q", got, w)
+			}
+		default:
+			t.Fatalf("missing relayed event %q", w)
+		}
+	}
+	select {
+	case e := <-sub.C:
+		t.Errorf("unexpected relayed event %+v", e)
+	default:
+	}
+}
+
+func TestReceiveEventsCommittedOutOfOrder(t *testing.T) {
+	dbtest.Open(t, &models.CrawlEvent{})
+	sub := events.Subscribe(0)
+	defer sub.Close()
+
+	insert := func(id int, status models.URLStatus) {
+		data := fmt.Sprintf(`{"type":"status","url_id":%d,"status":%q}`, id, status)
+		if err := db.DB.Create(&models.CrawlEvent{ID: id, URLID: id, Event: data}).Error; err != nil {
+			t.Fatalf("failed to insert event: %v", err)
+		}
+	}
+	received := func() []int {
+		var ids []int
+		for {
+			select {
+			case e := <-sub.C:
+				ids = append(ids, e.URLID)
+			default:
+				return ids
+			}
+		}
+	}
+
+	r := newEventReceiver(0)
+	now := time.Now()
+
+	// Event 2 commits after event 3 was read, but before event 3 has settled
+	insert(1, models.URLStatusDone)
+	insert(3, models.URLStatusDone)
+	r.receive(now)
+	insert(2, models.URLStatusError)
+	r.receive(now.Add(time.Second))
+	if got := received(); !slices.Equal(got, []int{1, 3, 2}) {
+		t.Errorf("received events %v, want [1 3 2]", got)
+	}
+
+	// Once settled, events are no longer read again
+	r.receive(now.Add(eventSettleTime + time.Second))
+	if r.horizon != 3 || len(r.seen) != 0 {
+		t.Errorf("horizon %d with %d events still tracked, want 3 and none", r.horizon, len(r.seen))
+	}
+	if got := received(); len(got) != 0 {
+		t.Errorf("events %v published twice", got)
+	}
+}
@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"strings"
+
+	"urlcrawler/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// resourceSelectors lists the elements that reference page sub-resources
+// and the attribute holding each reference.
+var resourceSelectors = []struct {
+	Type     models.ResourceType
+	Selector string
+	Attr     string
+}{
+	{models.ResourceScript, "script[src]", "src"},
+	{models.ResourceStylesheet, `link[rel~="stylesheet"]`, "href"},
+	{models.ResourceFont, `link[rel~="preload"][as="font"]`, "href"},
+	{models.ResourceIframe, "iframe[src]", "src"},
+	{models.ResourceVideo, "video[src], video source[src]", "src"},
+	{models.ResourceAudio, "audio[src], audio source[src]", "src"},
+	{models.ResourceFavicon, `link[rel~="icon"], link[rel="apple-touch-icon"]`, "href"},
+}
+
+// pageResource is a sub-resource reference extracted from a page.
+type pageResource struct {
+	Type models.ResourceType
+	Href string
+}
+
+// extractResources collects the sub-resources referenced by the document,
+// skipping empty references.
+func extractResources(doc *goquery.Document) []pageResource {
+	var resources []pageResource
+
+	for _, rs := range resourceSelectors {
+		doc.Find(rs.Selector).Each(func(i int, s *goquery.Selection) {
+			href, exists := s.Attr(rs.Attr)
+			if !exists || strings.TrimSpace(href) == "" {
+				return
+			}
+			resources = append(resources, pageResource{Type: rs.Type, Href: href})
+		})
+	}
+
+	return resources
+}
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"urlcrawler/internal/config"
+)
+
+// transientStatuses are responses worth retrying: rate limiting and gateway/availability errors.
+var transientStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// isTransient reports whether a failed request may succeed if sent again.
+func isTransient(resp *http.Response, err error) bool {
+	if err == nil {
+		return transientStatuses[resp.StatusCode]
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true // Server closed the connection mid-response
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the wait before the given retry (1 for the first retry):
+// exponential backoff capped at the configured maximum, with jitter over its upper half.
+// A Retry-After header in seconds takes precedence, within the same cap. Negative
+// settings count as zero and a maximum below the base delay as the base delay.
+func retryDelay(retry int, resp *http.Response) time.Duration {
+	baseDelay := time.Duration(max(config.Cfg.RetryBaseDelayMs, 0)) * time.Millisecond
+	maxDelay := max(time.Duration(config.Cfg.RetryMaxDelayMs)*time.Millisecond, baseDelay)
+
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, maxDelay)
+		}
+	}
+
+	delay := baseDelay << (retry - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// doWithRetry sends the request built by send, retrying transient failures with
+// backoff up to the configured number of attempts. It returns the last response
+// or error along with the number of attempts made.
+func doWithRetry(ctx context.Context, send func() (*http.Response, error)) (*http.Response, int, error) {
+	maxAttempts := max(config.Cfg.RetryMaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		if attempt >= maxAttempts || ctx.Err() != nil || !isTransient(resp, err) {
+			return resp, attempt, err
+		}
+
+		delay := retryDelay(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"urlcrawler/internal/config"
+)
+
+func TestRetryDelayClampsConfig(t *testing.T) {
+	defer func(saved config.Config) { config.Cfg = saved }(config.Cfg)
+
+	tests := []struct {
+		baseMs, maxMs int
+		min, max      time.Duration
+	}{
+		{baseMs: 500, maxMs: 8000, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{baseMs: 500, maxMs: 100, min: 250 * time.Millisecond, max: 500 * time.Millisecond}, // Maximum below the base
+		{baseMs: 500, maxMs: -1, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{baseMs: -500, maxMs: -1, min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		config.Cfg.RetryBaseDelayMs = tt.baseMs
+		config.Cfg.RetryMaxDelayMs = tt.maxMs
+		if delay := retryDelay(1, nil); delay < tt.min || delay > tt.max {
+			t.Errorf("base %dms, max %dms: delay %v not within [%v, %v]", tt.baseMs, tt.maxMs, delay, tt.min, tt.max)
+		}
+	}
+}
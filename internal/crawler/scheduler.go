@@ -0,0 +1,95 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ParseSchedule parses a URL's crawl schedule: a standard 5-field cron expression,
+// a descriptor such as "@daily", or an interval such as "@every 6h" or just "6h".
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, err := time.ParseDuration(spec); err == nil {
+		spec = "@every " + d.String()
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, err
+	}
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok && every.Delay < time.Minute {
+		return nil, fmt.Errorf("interval must be at least 1m")
+	}
+	return schedule, nil
+}
+
+// NextRun returns the next time a schedule is due after t, or nil if spec is empty or invalid.
+func NextRun(spec string, t time.Time) *time.Time {
+	if spec == "" {
+		return nil
+	}
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return nil
+	}
+	next := schedule.Next(t)
+	return &next
+}
+
+// RunScheduler queues crawls of scheduled URLs as they become due until ctx is cancelled.
+func RunScheduler(ctx context.Context) {
+	tick := time.Duration(config.Cfg.SchedulerTickSeconds) * time.Second
+	if tick <= 0 {
+		tick = 30 * time.Second
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	log.Printf("⏰ Crawl scheduler started, checking every %s", tick)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			startDueCrawls(now)
+		}
+	}
+}
+
+// startDueCrawls queues a crawl of every URL whose next run time has passed.
+// The next run is always moved forward, so a URL still being crawled skips the
+// missed slot instead of queueing an overlapping run.
+func startDueCrawls(now time.Time) {
+	urls, err := models.GetDueScheduledURLs(now)
+	if err != nil {
+		log.Printf("⚠️ Scheduler failed to load due URLs: %v", err)
+		return
+	}
+
+	for _, u := range urls {
+		next := NextRun(u.Schedule, now)
+
+		// A paused URL waits to be resumed; a scheduled crawl would discard its checkpoint
+		var lastRun *time.Time
+		switch {
+		case u.Status == models.URLStatusPaused:
+			log.Printf("⏭️ Skipping scheduled crawl of URL %d: crawl is paused", u.ID)
+		case Enqueue(Job{URLID: u.ID, UserID: u.UserID, Priority: PriorityNormal}):
+			lastRun = &now
+		default:
+			log.Printf("⏭️ Skipping scheduled crawl of URL %d: previous crawl still queued or running", u.ID)
+		}
+
+		if err := models.UpdateURLScheduleTimes(u.ID, next, lastRun); err != nil {
+			log.Printf("⚠️ Scheduler failed to update URL %d: %v", u.ID, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"urlcrawler/internal/models"
+)
+
+// telNumberRegex matches a global or local phone number with common visual separators.
+var telNumberRegex = regexp.MustCompile(`^\+?[0-9()\-. ]*[0-9][0-9()\-. ]*$`)
+
+// classifyLink returns the kind of a resolved link based on its scheme.
+// Links without a scheme could not be resolved and are still treated as http
+// so that the network check reports them.
+func classifyLink(u *url.URL) models.LinkKind {
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "":
+		return models.LinkKindHTTP
+	case "mailto":
+		return models.LinkKindMailto
+	case "tel":
+		return models.LinkKindTel
+	case "javascript":
+		return models.LinkKindJavaScript
+	case "data":
+		return models.LinkKindData
+	default:
+		return models.LinkKindOther
+	}
+}
+
+// isValidMailto checks every recipient of a mailto: link. A link without recipients
+// in its path is valid only when it supplies them through a "to" query field.
+func isValidMailto(u *url.URL) bool {
+	if u.Opaque == "" {
+		values, err := url.ParseQuery(u.RawQuery)
+		return err == nil && values.Get("to") != ""
+	}
+
+	recipients, err := url.PathUnescape(u.Opaque)
+	if err != nil {
+		return false
+	}
+	for _, addr := range strings.Split(recipients, ",") {
+		if _, err := mail.ParseAddress(strings.TrimSpace(addr)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidTel checks the number of a tel: link, ignoring parameters such as ";ext=".
+func isValidTel(u *url.URL) bool {
+	number, err := url.PathUnescape(u.Opaque)
+	if err != nil {
+		return false
+	}
+	number, _, _ = strings.Cut(number, ";")
+	return telNumberRegex.MatchString(strings.TrimSpace(number))
+}
+
+// checkNonHTTPLink validates a link that is not checked over the network.
+// Only mailto: and tel: links have a syntax that can be verified.
+func checkNonHTTPLink(u *url.URL, kind models.LinkKind) models.LinkIssue {
+	switch {
+	case kind == models.LinkKindMailto && !isValidMailto(u):
+		return models.LinkIssueInvalidSyntax
+	case kind == models.LinkKindTel && !isValidTel(u):
+		return models.LinkIssueInvalidSyntax
+	default:
+		return models.LinkIssueNone
+	}
+}
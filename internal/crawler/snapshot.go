@@ -0,0 +1,35 @@
+package crawler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// contentHash returns the SHA-256 of the page HTML after removing the elements matched
+// by the comma separated volatile selectors (timestamps, ads, CSRF tokens...) and
+// collapsing whitespace, so that only meaningful changes alter the hash.
+func contentHash(body []byte, volatileSelectors string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	for _, selector := range strings.Split(volatileSelectors, ",") {
+		if selector = strings.TrimSpace(selector); selector != "" {
+			doc.Find(selector).Remove()
+		}
+	}
+
+	html, err := doc.Html()
+	if err != nil {
+		return "", err
+	}
+	normalized := strings.Join(strings.Fields(html), " ")
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
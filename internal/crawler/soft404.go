@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// notFoundTextRegex matches titles and headings typical of error pages served with a 200 status.
+var notFoundTextRegex = regexp.MustCompile(`(?i)\b(404|not found|page (does not|doesn't) exist|no longer (exists|available)|page unavailable)\b`)
+
+// soft404SizeTolerance is the relative body size difference within which a page
+// is considered to be the same template as the not-found probe.
+const soft404SizeTolerance = 0.1
+
+// soft404Detector recognises internal links that return 200 with a "not found" page.
+// Each host is probed once with a random path to learn what its not-found page looks like.
+type soft404Detector struct {
+	pages  *pageCache
+	probes map[string]*fetchedPage // Keyed by scheme and host; nil if the probe failed
+}
+
+func newSoft404Detector(pages *pageCache) *soft404Detector {
+	return &soft404Detector{pages: pages, probes: map[string]*fetchedPage{}}
+}
+
+// probe fetches a path that should not exist on the host of target.
+func (d *soft404Detector) probe(ctx context.Context, target *url.URL) *fetchedPage {
+	key := target.Scheme + "://" + target.Host
+	if page, seen := d.probes[key]; seen {
+		return page
+	}
+
+	random := make([]byte, 12)
+	rand.Read(random)
+	probeURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/" + hex.EncodeToString(random)}
+
+	page := fetchPage(ctx, d.pages.client, probeURL.String())
+	d.probes[key] = page
+	return page
+}
+
+// isSoft404 reports whether the internal page at target looks like a not-found page,
+// either by its title or first heading, or by matching the host's not-found probe.
+func (d *soft404Detector) isSoft404(ctx context.Context, target *url.URL) bool {
+	page := d.pages.get(ctx, target)
+	if page == nil || page.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if notFoundTextRegex.MatchString(page.Title) || notFoundTextRegex.MatchString(page.Heading) {
+		return true
+	}
+
+	// Only hosts that answer a random path with 200 can serve soft 404s
+	probe := d.probe(ctx, target)
+	if probe == nil || probe.StatusCode != http.StatusOK {
+		return false
+	}
+	return page.Title == probe.Title && sizesSimilar(page.BodySize, probe.BodySize)
+}
+
+// sizesSimilar reports whether two body sizes differ by at most soft404SizeTolerance.
+func sizesSimilar(a, b int) bool {
+	larger, smaller := a, b
+	if smaller > larger {
+		larger, smaller = smaller, larger
+	}
+	if larger == 0 {
+		return true
+	}
+	return float64(larger-smaller)/float64(larger) <= soft404SizeTolerance
+}
@@ -0,0 +1,100 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"urlcrawler/internal/models"
+)
+
+// pageTimings collects the connection phase timestamps of a single request.
+type pageTimings struct {
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	done         time.Time
+}
+
+// withTimings returns a context that records connection phase timestamps into the returned pageTimings.
+func withTimings(ctx context.Context) (context.Context, *pageTimings) {
+	t := &pageTimings{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { t.connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { t.connectDone = time.Now() },
+		TLSHandshakeStart:    func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.tlsDone = time.Now() },
+		GotConn:              func(httptrace.GotConnInfo) { t.gotConn = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { t.firstByte = time.Now() },
+	}
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+// reset clears the recorded timestamps before a new attempt of the same request.
+func (t *pageTimings) reset() {
+	*t = pageTimings{start: time.Now()}
+}
+
+// millisBetween returns the milliseconds between two timestamps, or 0 if either was not recorded.
+func millisBetween(from, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
+
+// applyTo copies the recorded phase durations onto a crawl run.
+// Phases skipped because of a reused connection are left at 0.
+func (t *pageTimings) applyTo(run *models.CrawlRun) {
+	run.DNSMs = millisBetween(t.dnsStart, t.dnsDone)
+	run.ConnectMs = millisBetween(t.connectStart, t.connectDone)
+	run.TLSMs = millisBetween(t.tlsStart, t.tlsDone)
+	run.TTFBMs = millisBetween(t.start, t.firstByte)
+	run.DownloadMs = millisBetween(t.firstByte, t.done)
+	run.TotalMs = millisBetween(t.start, t.done)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readBody reads the response body, decoding gzip when the request asked for it
+// explicitly, and returns the decoded bytes along with the bytes received on the wire.
+func readBody(resp *http.Response) ([]byte, int64, error) {
+	raw := &countingReader{r: resp.Body}
+
+	var body io.Reader = raw
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(raw)
+		if err != nil {
+			return nil, raw.n, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	bodyBytes, err := io.ReadAll(body)
+	return bodyBytes, raw.n, err
+}
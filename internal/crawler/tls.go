@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/models"
+)
+
+// isCertificateError reports whether err was caused by the server certificate failing verification.
+func isCertificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	return errors.As(err, &verifyErr)
+}
+
+// probeTLS connects to the page host without verifying the certificate so the
+// chain can still be inspected when the regular fetch rejected it.
+func probeTLS(ctx context.Context, pageURL *url.URL) (*tls.ConnectionState, error) {
+	host := pageURL.Hostname()
+	port := pageURL.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // Only used to read the certificates, never to fetch content
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	return &state, nil
+}
+
+// keyType describes the public key algorithm and size of a certificate.
+func keyType(cert *x509.Certificate) string {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", pub.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA-" + pub.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return cert.PublicKeyAlgorithm.String()
+	}
+}
+
+// buildCertificate converts the TLS connection state of a page fetch into a Certificate
+// record, checking the leaf against the page hostname and the configured expiry window.
+func buildCertificate(state *tls.ConnectionState, pageURL *url.URL, verifyErr error) *models.Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	leaf := state.PeerCertificates[0]
+	window := time.Duration(config.Cfg.CertExpiryWarnDays) * 24 * time.Hour
+
+	cert := &models.Certificate{
+		TLSVersion:    tls.VersionName(state.Version),
+		CipherSuite:   tls.CipherSuiteName(state.CipherSuite),
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		NotBefore:     leaf.NotBefore,
+		NotAfter:      leaf.NotAfter,
+		HostnameMatch: leaf.VerifyHostname(pageURL.Hostname()) == nil,
+		ExpiresSoon:   time.Until(leaf.NotAfter) < window,
+	}
+	if verifyErr != nil {
+		cert.VerifyError = verifyErr.Error()
+	}
+
+	for _, c := range state.PeerCertificates {
+		cert.Chain = append(cert.Chain, models.ChainCertificate{
+			Subject:   c.Subject.String(),
+			Issuer:    c.Issuer.String(),
+			SANs:      c.DNSNames,
+			NotBefore: c.NotBefore,
+			NotAfter:  c.NotAfter,
+			KeyType:   keyType(c),
+		})
+	}
+
+	return cert
+}
+
+// recordCertificate replaces the stored certificate of a URL with the one presented
+// during this crawl. Nothing is stored for plain HTTP pages.
+func recordCertificate(urlID int, state *tls.ConnectionState, pageURL *url.URL, verifyErr error) error {
+	if err := models.DeleteCertificateByURLID(urlID); err != nil {
+		return fmt.Errorf("failed to delete old certificate: %w", err)
+	}
+
+	cert := buildCertificate(state, pageURL, verifyErr)
+	if cert == nil {
+		return nil
+	}
+	cert.URLID = urlID
+	return models.InsertCertificate(*cert)
+}
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+func TestBuildCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	resp.Body.Close()
+	pageURL, _ := url.Parse(srv.URL)
+
+	config.Cfg.CertExpiryWarnDays = 30
+	cert := buildCertificate(resp.TLS, pageURL, nil)
+	if cert == nil {
+		t.Fatal("no certificate built for an HTTPS response")
+	}
+
+	leaf := resp.TLS.PeerCertificates[0]
+	if !cert.NotAfter.Equal(leaf.NotAfter) || !cert.NotBefore.Equal(leaf.NotBefore) {
+		t.Errorf("validity = %v - %v, want %v - %v", cert.NotBefore, cert.NotAfter, leaf.NotBefore, leaf.NotAfter)
+	}
+	if !cert.HostnameMatch {
+		t.Error("certificate does not match the 127.0.0.1 SAN it was issued for")
+	}
+	if cert.ExpiresSoon {
+		t.Error("certificate reported as expiring within 30 days")
+	}
+	if cert.TLSVersion == "" || cert.CipherSuite == "" {
+		t.Errorf("missing connection details: version %q, cipher %q", cert.TLSVersion, cert.CipherSuite)
+	}
+	if len(cert.Chain) != len(resp.TLS.PeerCertificates) {
+		t.Fatalf("chain has %d certificates, want %d", len(cert.Chain), len(resp.TLS.PeerCertificates))
+	}
+	if got := cert.Chain[0]; got.Subject != leaf.Subject.String() || got.KeyType == "" || len(got.SANs) == 0 {
+		t.Errorf("leaf chain entry = %+v", got)
+	}
+
+	// A window reaching past the expiry date flags the certificate
+	config.Cfg.CertExpiryWarnDays = 100 * 365
+	if cert := buildCertificate(resp.TLS, pageURL, nil); !cert.ExpiresSoon {
+		t.Error("certificate not reported as expiring within the window")
+	}
+}
+
+func TestRecordCertificateHostnameMismatch(t *testing.T) {
+	dbtest.Open(t, &models.Certificate{})
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+
+	// The test certificate is issued for 127.0.0.1 and example.com, not localhost
+	pageURL, _ := url.Parse(strings.Replace(srv.URL, "127.0.0.1", "localhost", 1))
+	_, fetchErr := srv.Client().Get(pageURL.String())
+	if !isCertificateError(fetchErr) {
+		t.Fatalf("fetch error = %v, want a certificate verification error", fetchErr)
+	}
+
+	state, err := probeTLS(context.Background(), pageURL)
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if err := recordCertificate(1, state, pageURL, fetchErr); err != nil {
+		t.Fatalf("failed to record certificate: %v", err)
+	}
+
+	cert, err := models.GetCertificateByURLID(1)
+	if err != nil {
+		t.Fatalf("certificate not stored: %v", err)
+	}
+	if cert.HostnameMatch {
+		t.Error("certificate stored as matching localhost")
+	}
+	if cert.VerifyError == "" {
+		t.Error("verification error not stored")
+	}
+	if len(cert.Chain) == 0 {
+		t.Error("chain not stored")
+	}
+}
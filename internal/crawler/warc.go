@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// warcWriter archives the exchanges of a crawl as a WARC 1.1 file in which
+// every record is a separate gzip member.
+type warcWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newWARCWriter creates the WARC file at path and writes its warcinfo record.
+func newWARCWriter(path string) (*warcWriter, error) {
+	return openWARCWriter(path, os.O_TRUNC)
+}
+
+// appendWARCWriter continues the WARC file at path, written by the run before it
+// was paused. The file is started anew if it is missing.
+func appendWARCWriter(path string) (*warcWriter, error) {
+	return openWARCWriter(path, os.O_APPEND)
+}
+
+func openWARCWriter(path string, flag int) (*warcWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w := &warcWriter{file: file}
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		return w, nil // Continued; the file already starts with its warcinfo record
+	}
+
+	info := "software: urlcrawler\r\nformat: WARC File Format 1.1\r\n"
+	err = w.writeRecord([][2]string{
+		{"WARC-Type", "warcinfo"},
+		{"WARC-Record-ID", newRecordID()},
+		{"WARC-Date", warcDate(time.Now())},
+		{"WARC-Filename", filepath.Base(path)},
+		{"Content-Type", "application/warc-fields"},
+	}, []byte(info))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// record writes a request record and, when a response was received, the matching response record.
+func (w *warcWriter) record(ex *exchange) {
+	date := warcDate(ex.Started)
+	target := ex.RequestURL.String()
+	requestID := newRecordID()
+
+	err := w.writeRecord([][2]string{
+		{"WARC-Type", "request"},
+		{"WARC-Record-ID", requestID},
+		{"WARC-Date", date},
+		{"WARC-Target-URI", target},
+		{"Content-Type", "application/http;msgtype=request"},
+	}, requestBlock(ex))
+	if err != nil || ex.Response == nil {
+		logWARCError(err)
+		return
+	}
+
+	headers := [][2]string{
+		{"WARC-Type", "response"},
+		{"WARC-Record-ID", newRecordID()},
+		{"WARC-Date", date},
+		{"WARC-Target-URI", target},
+		{"WARC-Concurrent-To", requestID},
+		{"Content-Type", "application/http;msgtype=response"},
+	}
+	if !ex.Complete {
+		// Link checks close bodies unread; the record holds only what was received
+		headers = append(headers, [2]string{"WARC-Truncated", "unspecified"})
+	}
+	logWARCError(w.writeRecord(headers, responseBlock(ex)))
+}
+
+// requestBlock renders the HTTP request as sent, without credentials: credential
+// headers are redacted and so is the body, which only login forms send.
+func requestBlock(ex *exchange) []byte {
+	req := ex.Request
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, ex.RequestURL.RequestURI(), req.URL.Host)
+
+	ex.RequestHeader.Write(&buf)
+	buf.WriteString("\r\n")
+	if len(ex.RequestBody) > 0 {
+		buf.WriteString("[redacted]")
+	}
+	return buf.Bytes()
+}
+
+// responseBlock renders the HTTP response status line, headers and the body read by
+// the crawler. Cookie values are redacted as they may be the session of a login.
+func responseBlock(ex *exchange) []byte {
+	resp := ex.Response
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	redactSetCookie(resp.Header).Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(ex.ResponseBody)
+	return buf.Bytes()
+}
+
+// writeRecord appends one gzip-compressed WARC record.
+func (w *warcWriter) writeRecord(headers [][2]string, block []byte) error {
+	digest := sha1.Sum(block)
+
+	var record bytes.Buffer
+	record.WriteString("WARC/1.1\r\n")
+	for _, h := range headers {
+		fmt.Fprintf(&record, "%s: %s\r\n", h[0], h[1])
+	}
+	fmt.Fprintf(&record, "WARC-Block-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	fmt.Fprintf(&record, "Content-Length: %d\r\n\r\n", len(block))
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	gz := gzip.NewWriter(w.file)
+	if _, err := gz.Write(record.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Close closes the underlying file.
+func (w *warcWriter) Close() error {
+	return w.file.Close()
+}
+
+// newRecordID returns a random UUID URN for a WARC-Record-ID.
+func newRecordID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// warcDate formats a timestamp as required by WARC-Date.
+func warcDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func logWARCError(err error) {
+	if err != nil {
+		log.Printf("⚠️ Failed to write WARC record: %v", err)
+	}
+}
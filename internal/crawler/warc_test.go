@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumedWARCContinuesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.warc.gz")
+	record := func(w *warcWriter, target string) {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		w.record(&exchange{Request: req, RequestURL: req.URL, RequestHeader: http.Header{}})
+		w.Close()
+	}
+
+	// Before the pause
+	w, err := newWARCWriter(path)
+	if err != nil {
+		t.Fatalf("failed to create WARC file: %v", err)
+	}
+	record(w, "https://example.com/before")
+
+	// After resuming
+	w, err = appendWARCWriter(path)
+	if err != nil {
+		t.Fatalf("failed to continue WARC file: %v", err)
+	}
+	record(w, "https://example.com/after")
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read WARC file: %v", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("failed to open WARC file: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to read WARC file: %v", err)
+	}
+
+	if n := bytes.Count(data, []byte("WARC-Type: warcinfo")); n != 1 {
+		t.Errorf("WARC file has %d warcinfo records, want 1", n)
+	}
+	for _, target := range []string{"/before", "/after"} {
+		if !bytes.Contains(data, []byte("WARC-Target-URI: https://example.com"+target)) {
+			t.Errorf("WARC file has no record of %s", target)
+		}
+	}
+}
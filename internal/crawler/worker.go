@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/events"
 	"urlcrawler/internal/models"
 
 	"github.com/PuerkitoBio/goquery"
@@ -38,7 +40,13 @@ func detectHTMLVersion(rawHTML string) string {
 	}
 }
 
-func ProcessURL(ctx context.Context, urlID int) error {
+// Options control optional behaviour of a single crawl.
+type Options struct {
+	WARC   bool // Archive every request and response of the crawl to a WARC file
+	Resume bool // Continue the paused run of the URL from its checkpoint, if it has one
+}
+
+func ProcessURL(ctx context.Context, urlID int, opts Options) (err error) {
 	fmt.Printf("Processing URL ID %d\n", urlID)
 
 	// 1. Get URL from DB
@@ -47,19 +55,133 @@ func ProcessURL(ctx context.Context, urlID int) error {
 		return fmt.Errorf("failed to get URL from DB: %w", err)
 	}
 
-	// 2. Fetch page
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, urlObj.URL, nil)
-	resp, err := http.DefaultClient.Do(req)
+	// 1.a Record this crawl as a run, or continue the paused run when resuming;
+	// its final status follows the returned error. A crawl that starts over discards
+	// the checkpoint; a resumed one keeps it until it checks the saved frontier.
+	var checkpoint *models.CrawlCheckpoint
+	if opts.Resume {
+		checkpoint, err = models.GetCrawlCheckpoint(urlID)
+		if err != nil {
+			return fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+	} else if err := models.DeleteCrawlCheckpoint(urlID); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	var run *models.CrawlRun
+	if checkpoint != nil {
+		run, err = models.ResumeCrawlRun(urlID, checkpoint.RunID)
+	} else {
+		run, err = models.StartCrawlRun(urlID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start crawl run: %w", err)
+	}
+	defer func() {
+		models.FinishCrawlRun(run, err)
+	}()
+
+	pageURL, err := url.Parse(urlObj.URL)
+	if err != nil {
+		return fmt.Errorf("invalid page URL: %w", err)
+	}
+
+	// 1.b Build this crawl's HTTP client with the URL's credentials, if any,
+	// recording its traffic as a HAR and, when the crawl is archived, a WARC file
+	creds, err := models.GetCrawlCredentials(urlID)
+	if err != nil {
+		return fmt.Errorf("failed to load crawl credentials: %w", err)
+	}
+	har := &harRecorder{}
+	if checkpoint != nil {
+		har.load(urlID, run.ID)
+	}
+	recorders := []exchangeRecorder{har}
+	defer saveHAR(urlID, run.ID, har) // Also on failure, when the HAR is most useful
+	// A resumed run continues the WARC file it started before the pause, found in
+	// this process's WARC_DIR
+	var warc *warcWriter
+	switch {
+	case checkpoint != nil && run.WARCPath != "":
+		warc, err = appendWARCWriter(filepath.Join(config.Cfg.WARCDir, filepath.Base(run.WARCPath)))
+	case checkpoint == nil && opts.WARC:
+		run.WARCPath = filepath.Join(config.Cfg.WARCDir, fmt.Sprintf("url-%d-run-%d.warc.gz", urlID, run.ID))
+		warc, err = newWARCWriter(run.WARCPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open WARC file: %w", err)
+	}
+	if warc != nil {
+		defer warc.Close()
+		recorders = append(recorders, warc)
+	}
+	client := newCrawlClient(pageURL, creds, recorders...)
+
+	// 1.c Sign in through the login form first so the session cookie is in the client's jar
+	if creds != nil && creds.Login != nil {
+		if err := performLogin(ctx, client, creds.Login); err != nil {
+			return err
+		}
+	}
+
+	// 1.d Resuming: check the rest of the saved frontier against the stored snapshot
+	if checkpoint != nil {
+		return resumeFromCheckpoint(ctx, client, urlObj, run, pageURL, checkpoint)
+	}
+
+	// 2. Fetch page, tracing connection phases for the performance metrics
+	reportProgress(urlID, events.Progress{Phase: events.PhaseFetching})
+	// Transient failures are retried; timings cover the last attempt only
+	traceCtx, timings := withTimings(ctx)
+	resp, _, err := doWithRetry(ctx, func() (*http.Response, error) {
+		timings.reset()
+		req, _ := http.NewRequestWithContext(traceCtx, http.MethodGet, urlObj.URL, nil)
+		req.Header.Set("Accept-Encoding", "gzip") // Set explicitly so the compressed size can be measured
+		// Revalidate the previous report instead of downloading an unchanged page again
+		if urlObj.ETag != "" {
+			req.Header.Set("If-None-Match", urlObj.ETag)
+		}
+		if urlObj.LastModified != "" {
+			req.Header.Set("If-Modified-Since", urlObj.LastModified)
+		}
+		return client.Do(req)
+	})
 	if err != nil {
+		// Keep the rejected certificate so the failure can be inspected
+		if isCertificateError(err) {
+			if state, probeErr := probeTLS(ctx, pageURL); probeErr == nil {
+				recordCertificate(urlID, state, pageURL, err)
+			}
+		}
 		return fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// 2.a Read raw body bytes for HTML version detection and goquery parsing
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// 2.a Record the TLS certificate presented for HTTPS pages
+	if err := recordCertificate(urlID, resp.TLS, pageURL, nil); err != nil {
+		return fmt.Errorf("failed to store certificate: %w", err)
+	}
+
+	// 2.b The page has not changed since the last analysed crawl: keep the previous report
+	if resp.StatusCode == http.StatusNotModified {
+		timings.done = time.Now()
+		timings.applyTo(run)
+		return keepPreviousReport(urlObj, run)
+	}
+
+	// 2.c Read raw body bytes for HTML version detection and goquery parsing
+	bodyBytes, transferSize, err := readBody(resp)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
+	timings.done = time.Now()
+	timings.applyTo(run)
+	run.TransferSize = transferSize
+	run.ContentSize = int64(len(bodyBytes))
+	run.PageWeight = transferSize
+
+	// 2.d Keep the validators for the next crawl; they are saved with the completed report
+	urlObj.ETag = resp.Header.Get("ETag")
+	urlObj.LastModified = resp.Header.Get("Last-Modified")
 
 	// 3. Detect HTML version
 	htmlVersion := detectHTMLVersion(string(bodyBytes))
@@ -72,16 +194,78 @@ func ProcessURL(ctx context.Context, urlID int) error {
 	}
 
 	// 5. Extract page title
+	reportProgress(urlID, events.Progress{Phase: events.PhaseAnalysis})
 	title := strings.TrimSpace(doc.Find("title").Text())
 	urlObj.Title = title
 
-	// 6. Clean old data
+	// 5.a Store response headers and audit them
+	if err := models.DeleteSecurityAuditByURLID(urlID); err != nil {
+		return fmt.Errorf("failed to delete old security audit: %w", err)
+	}
+	checks := auditSecurityHeaders(resp)
+	models.InsertSecurityAudit(models.SecurityAudit{
+		URLID:   urlID,
+		Headers: redactSetCookie(resp.Header),
+		Checks:  checks,
+		Grade:   gradeSecurityChecks(checks),
+	})
+
+	// 5.b Snapshot the page and compare its normalized content with the previous crawl
+	run.ResourceCount = len(extractResources(doc))
+	run.ContentHash, err = contentHash(bodyBytes, urlObj.VolatileSelectors)
+	if err != nil {
+		return fmt.Errorf("failed to hash page content: %w", err)
+	}
+	if err := models.InsertPageSnapshot(urlID, run.ID, run.ContentHash, bodyBytes); err != nil {
+		return fmt.Errorf("failed to store page snapshot: %w", err)
+	}
+	previous, err := models.GetPreviousAnalysedRun(urlID, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load previous crawl run: %w", err)
+	}
+	run.ContentChanged = previous == nil || previous.ContentHash != run.ContentHash
+
+	if run.ContentChanged {
+		// 6-10. Analyse the document: headings, links, sub-resources and mixed content
+		page := summarisePage(resp.StatusCode, doc, len(bodyBytes))
+		if err := analyzeDocument(ctx, client, urlObj, run, pageURL, doc, page); err != nil {
+			return err
+		}
+	} else {
+		// Unchanged content: keep the previous report and its sub-resource weight
+		run.AnalysisSkipped = true
+		run.PageWeight += previous.PageWeight - previous.TransferSize
+	}
+
+	// 11. Update URL with status, HTML version and the validators for the next crawl
+	urlObj.Status = models.URLStatusDone
+	urlObj.UpdatedAt = time.Now()
+	if err := models.UpdateURLCrawlResult(urlObj); err != nil {
+		return fmt.Errorf("failed to update URL status: %w", err)
+	}
+
+	fmt.Printf("Finished processing URL ID %d\n", urlID)
+	return nil
+}
+
+// analyzeDocument replaces the stored report of a URL with the headings, links,
+// sub-resources and mixed content found in the parsed page.
+func analyzeDocument(ctx context.Context, client *http.Client, urlObj *models.URL, run *models.CrawlRun, pageURL *url.URL, doc *goquery.Document, page *fetchedPage) error {
+	urlID := urlObj.ID
+
+	// 6. Clean old data; validators are dropped until the new report is complete
+	if err := models.ClearURLValidators(urlID); err != nil {
+		return fmt.Errorf("failed to clear URL validators: %w", err)
+	}
 	if err := models.DeleteLinksByURLID(urlID); err != nil {
 		return fmt.Errorf("failed to delete old links: %w", err)
 	}
 	if err := models.DeleteHeadingsByURLID(urlID); err != nil {
 		return fmt.Errorf("failed to delete old headings: %w", err)
 	}
+	if err := models.DeleteMixedContentIssuesByURLID(urlID); err != nil {
+		return fmt.Errorf("failed to delete old mixed content issues: %w", err)
+	}
 
 	// 7. Extract and store headings
 	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
@@ -97,61 +281,45 @@ func ProcessURL(ctx context.Context, urlID int) error {
 		}
 	})
 
-	// 8. Extract and store links
-	pageURL, err := url.Parse(urlObj.URL)
-	if err != nil {
-		return fmt.Errorf("invalid page URL: %w", err)
+	// 8-9. Check links and sub-resources (scripts, stylesheets, fonts, iframes, media, favicons)
+	if err := checkFrontier(ctx, client, urlObj, run, pageURL, page, buildFrontier(doc, pageURL), 0); err != nil {
+		return err
 	}
 
-	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists || strings.TrimSpace(href) == "" {
-			return
-		}
-
-		absURL, err := pageURL.Parse(href)
-		if err != nil {
-			absURL = &url.URL{Path: href} // fallback
-		}
-
-		linkURL := absURL.String()
-		statusCode := 0
-		isBroken := true
+	// 10. Detect mixed content and insecure links on HTTPS pages
+	mixedContent := detectMixedContent(doc, pageURL)
+	for _, issue := range mixedContent {
+		issue.URLID = urlID
+		models.InsertMixedContentIssue(issue)
+	}
+	urlObj.MixedContentCount = len(mixedContent)
 
-		// Check if link is internal (same host) or external
-		isInternal := absURL.Host == pageURL.Host
+	return nil
+}
 
-		// Try HEAD first, fallback to GET if needed
-		req, _ := http.NewRequestWithContext(ctx, http.MethodHead, linkURL, nil)
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil || resp.StatusCode >= 400 {
-			// Retry with GET
-			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, linkURL, nil)
-			resp, err = http.DefaultClient.Do(req)
-		}
-		if err == nil {
-			statusCode = resp.StatusCode
-			isBroken = statusCode >= 400
-			resp.Body.Close()
-		}
+// keepPreviousReport completes a crawl answered with 304 Not Modified. The stored
+// report stays as is and the run carries over the metrics of the last analysed run.
+func keepPreviousReport(urlObj *models.URL, run *models.CrawlRun) error {
+	previous, err := models.GetPreviousAnalysedRun(urlObj.ID, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load previous crawl run: %w", err)
+	}
 
-		// Save link to DB with internal/external info
-		models.InsertLink(models.Link{
-			URLID:      urlID,
-			Href:       linkURL,
-			StatusCode: statusCode,
-			IsBroken:   isBroken,
-			IsInternal: isInternal,
-		})
-	})
+	run.NotModified = true
+	run.AnalysisSkipped = true
+	if previous != nil {
+		run.ContentHash = previous.ContentHash
+		run.ContentSize = previous.ContentSize
+		run.ResourceCount = previous.ResourceCount
+		run.PageWeight = previous.PageWeight - previous.TransferSize
+	}
 
-	// 9. Update URL with status and HTML version
 	urlObj.Status = models.URLStatusDone
 	urlObj.UpdatedAt = time.Now()
-	if err := models.UpdateURL(urlObj); err != nil {
+	if err := models.UpdateURLCrawlResult(urlObj); err != nil {
 		return fmt.Errorf("failed to update URL status: %w", err)
 	}
 
-	fmt.Printf("Finished processing URL ID %d\n", urlID)
+	fmt.Printf("URL ID %d not modified since last crawl\n", urlObj.ID)
 	return nil
 }
@@ -0,0 +1,39 @@
+// Package crawlhooks wires up what runs after each crawl, shared by the server and worker processes.
+package crawlhooks
+
+import (
+	"log"
+
+	"urlcrawler/internal/alerts"
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/notify"
+	"urlcrawler/internal/webhooks"
+)
+
+// RegisterCrawlHooks sets up what runs after each crawl finished by this process:
+// webhooks, email notifications if SMTP is configured, and alert rule evaluation.
+// Every process running crawl workers must call it.
+func RegisterCrawlHooks() {
+	// Notify registered webhooks of finished crawls and alert state changes
+	crawler.OnCrawlFinished(webhooks.HandleCrawlFinished)
+	alerts.OnStateChange(webhooks.HandleAlert)
+
+	// Email subscribers about new broken links, failed crawls and alert state changes
+	if config.Cfg.SMTPHost != "" {
+		notify.SetMailer(&notify.SMTPMailer{
+			Host:     config.Cfg.SMTPHost,
+			Port:     config.Cfg.SMTPPort,
+			Username: config.Cfg.SMTPUsername,
+			Password: config.Cfg.SMTPPassword,
+			From:     config.Cfg.SMTPFrom,
+		})
+		crawler.OnCrawlFinished(notify.HandleCrawlFinished)
+		alerts.OnStateChange(notify.HandleAlert)
+	} else {
+		log.Println("⚠️ SMTP_HOST not set. Email notifications are disabled.")
+	}
+
+	// Evaluate alert rules after each crawl
+	crawler.OnCrawlFinished(alerts.HandleCrawlFinished)
+}
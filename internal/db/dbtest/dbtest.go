@@ -0,0 +1,40 @@
+// Package dbtest backs the global database connection with SQLite for tests.
+package dbtest
+
+import (
+	"testing"
+
+	"urlcrawler/internal/db"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open points db.DB at a fresh in-memory database with tables for the given
+// models, restoring the previous connection when the test ends.
+func Open(t testing.TB, models ...any) {
+	t.Helper()
+
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // Every connection to :memory: would get its own empty database
+	if err := conn.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to create test tables: %v", err)
+	}
+
+	previous := db.DB
+	db.DB = conn
+	t.Cleanup(func() {
+		db.DB = previous
+		sqlDB.Close()
+	})
+}
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type names the kind of an event; it is used as the SSE event name.
+type Type string
+
+const (
+	TypeStatus   Type = "status"   // The status of a URL changed
+	TypeProgress Type = "progress" // A running crawl made progress
+)
+
+// Phase is the step a running crawl is in.
+type Phase string
+
+const (
+	PhaseFetching Phase = "fetching"       // Fetching the page
+	PhaseAnalysis Phase = "analysing"      // Auditing, snapshotting and extracting headings
+	PhaseLinks    Phase = "checking_links" // Checking links and sub-resources
+)
+
+// Progress describes how far a running crawl has got.
+type Progress struct {
+	Phase        Phase `json:"phase"`
+	LinksFound   int   `json:"links_found"`   // Links and sub-resources found on the page
+	LinksChecked int   `json:"links_checked"` // Of which checked so far
+	BrokenLinks  int   `json:"broken_links"`  // Broken so far
+}
+
+// Event is a status change or progress report of a URL crawl.
+type Event struct {
+	Type     Type      `json:"type"`
+	URLID    int       `json:"url_id"`
+	Status   string    `json:"status,omitempty"` // Set on status events
+	Error    string    `json:"error,omitempty"`
+	Progress *Progress `json:"progress,omitempty"` // Set on progress events
+	Time     time.Time `json:"time"`
+}
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before events are dropped for it.
+const subscriberBuffer = 64
+
+// Subscription receives the events of one URL, or of all URLs.
+type Subscription struct {
+	C     <-chan Event
+	c     chan Event
+	urlID int // 0 for all URLs
+}
+
+// bus fans out published events to subscribers without ever blocking the publisher.
+type bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+var defaultBus = &bus{subs: map[*Subscription]struct{}{}}
+
+// forward receives every published event as well, see Forward.
+var forward func(Event)
+
+// Forward makes Publish also hand every event to f, which must not block for long.
+// Worker processes use it to relay their events to the API server, whose
+// subscribers are in another process. It must be called before events are published.
+func Forward(f func(Event)) {
+	forward = f
+}
+
+// Subscribe returns a subscription to the events of a URL, or of all URLs if urlID is 0.
+// The subscription must be closed when no longer used.
+func Subscribe(urlID int) *Subscription {
+	c := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: c, c: c, urlID: urlID}
+
+	defaultBus.mu.Lock()
+	defaultBus.subs[sub] = struct{}{}
+	defaultBus.mu.Unlock()
+	return sub
+}
+
+// Close removes the subscription from the bus and closes its channel.
+func (s *Subscription) Close() {
+	defaultBus.mu.Lock()
+	defer defaultBus.mu.Unlock()
+	if _, ok := defaultBus.subs[s]; ok {
+		delete(defaultBus.subs, s)
+		close(s.c)
+	}
+}
+
+// Publish sends an event to every matching subscriber. Subscribers that are not
+// keeping up miss the event rather than slowing down the crawl.
+func Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	if forward != nil {
+		forward(e)
+	}
+
+	defaultBus.mu.RLock()
+	defer defaultBus.mu.RUnlock()
+	for sub := range defaultBus.subs {
+		if sub.urlID != 0 && sub.urlID != e.URLID {
+			continue
+		}
+		select {
+		case sub.c <- e:
+		default:
+		}
+	}
+}
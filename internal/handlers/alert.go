@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAlertRuleRequest adds an alert rule to a URL. The threshold is a number of
+// broken links, milliseconds or days depending on the kind, and unused otherwise.
+type CreateAlertRuleRequest struct {
+	Kind      models.AlertKind `json:"kind" binding:"required"`
+	Threshold int              `json:"threshold"`
+}
+
+// GetAlertRulesHandler handles GET /urls/:id/alerts
+// Returns the alert rules of a URL with their current state
+func GetAlertRulesHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	rules, err := models.GetAlertRulesByURLID(urlID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alert rules"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if rules == nil {
+		rules = []models.AlertRule{}
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateAlertRuleHandler handles POST /admin/urls/:id/alerts
+// Adds an alert rule evaluated after each crawl of the URL
+func CreateAlertRuleHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if !isAlertKind(req.Kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown alert kind: " + string(req.Kind)})
+		return
+	}
+	if !req.Kind.HasThreshold() {
+		req.Threshold = 0
+	} else if req.Threshold < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Threshold must not be negative"})
+		return
+	}
+
+	if _, err := models.GetURLByID(urlID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	userClaims, _ := c.Get("user")
+	rule := models.AlertRule{
+		URLID:     urlID,
+		Kind:      req.Kind,
+		Threshold: req.Threshold,
+		State:     models.AlertResolved,
+		CreatedBy: userClaims.(*auth.CustomClaims).UserID,
+	}
+	if err := models.InsertAlertRule(&rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func isAlertKind(kind models.AlertKind) bool {
+	for _, k := range models.AlertKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteAlertRuleHandler handles DELETE /admin/alerts/:id
+// Removes an alert rule
+func DeleteAlertRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert rule ID"})
+		return
+	}
+
+	if err := models.DeleteAlertRule(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted"})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"urlcrawler/internal/events"
+	"urlcrawler/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAlive is how often a comment is sent on an idle stream so proxies keep it open.
+const sseKeepAlive = 15 * time.Second
+
+// GetURLEventsHandler handles GET /urls/events
+// Streams status changes and crawl progress of all URLs as Server-Sent Events
+func GetURLEventsHandler(c *gin.Context) {
+	streamEvents(c, events.Subscribe(0))
+}
+
+// GetURLEventsByIDHandler handles GET /urls/:id/events
+// Streams status changes and crawl progress of one URL, starting with its current status
+func GetURLEventsByIDHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	urlRecord, err := models.GetURLByID(urlID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	// Subscribe before sending the current status so no change is missed in between
+	sub := events.Subscribe(urlID)
+	streamEvents(c, sub, events.Event{
+		Type:   events.TypeStatus,
+		URLID:  urlID,
+		Status: string(urlRecord.Status),
+		Error:  urlRecord.ErrorMessage,
+		Time:   time.Now(),
+	})
+}
+
+// streamEvents writes the initial events, then those of the subscription, to the client until it disconnects.
+func streamEvents(c *gin.Context, sub *events.Subscription, initial ...events.Event) {
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable response buffering in nginx
+	c.Status(http.StatusOK)
+	for _, e := range initial {
+		c.SSEvent(string(e.Type), e)
+	}
+	c.Writer.Flush() // Send the headers right away so the client sees the stream open
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e := <-sub.C:
+			c.SSEvent(string(e.Type), e)
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
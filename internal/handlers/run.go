@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCrawlRunsHandler handles GET /urls/:id/runs
+// Returns the crawl history of a URL with per-run performance metrics, most recent first
+func GetCrawlRunsHandler(c *gin.Context) {
+	urlIDStr := c.Param("id")
+	urlID, err := strconv.Atoi(urlIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	runs, err := models.GetCrawlRunsByURLID(urlID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crawl runs"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if runs == nil {
+		runs = []models.CrawlRun{}
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetCrawlRunHandler handles GET /urls/:id/runs/:runId
+// Returns a single crawl run of a URL
+func GetCrawlRunHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+	runID, err := strconv.Atoi(c.Param("runId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	run, err := models.GetCrawlRun(urlID, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Crawl run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetRunSnapshotHandler handles GET /urls/:id/runs/:runId/snapshot
+// Returns the HTML stored for a crawl run, sandboxed so its scripts cannot run in the API origin
+func GetRunSnapshotHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+	runID, err := strconv.Atoi(c.Param("runId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	html, err := models.GetPageSnapshotHTML(urlID, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+		return
+	}
+
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
+
+// GetRunWARCHandler handles GET /admin/urls/:id/runs/:runId/warc
+// Downloads the WARC archive written for a crawl run, if one was requested when starting it
+func GetRunWARCHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+	runID, err := strconv.Atoi(c.Param("runId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	run, err := models.GetCrawlRun(urlID, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Crawl run not found"})
+		return
+	}
+	if run.WARCPath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No WARC archive for this run"})
+		return
+	}
+	if run.Status == models.CrawlRunRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Crawl run still in progress"})
+		return
+	}
+
+	// Archives are looked up in this process's WARC_DIR, which worker processes must share
+	name := filepath.Base(run.WARCPath)
+	path := filepath.Join(config.Cfg.WARCDir, name)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "WARC archive not found in WARC_DIR"})
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
+
+// GetRunHARHandler handles GET /urls/:id/runs/:runId/har
+// Downloads an HTTP Archive of every request made during a crawl run, for browser devtools
+func GetRunHARHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+	runID, err := strconv.Atoi(c.Param("runId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return
+	}
+
+	har, err := models.GetCrawlRunHAR(urlID, runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "HAR not found"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="url-%d-run-%d.har"`, urlID, runID))
+	c.Data(http.StatusOK, "application/json", har)
+}
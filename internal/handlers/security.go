@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"urlcrawler/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMixedContentHandler handles GET /urls/:id/mixed-content
+// Returns the insecure references found on an HTTPS page along with their count
+func GetMixedContentHandler(c *gin.Context) {
+	urlIDStr := c.Param("id")
+	urlID, err := strconv.Atoi(urlIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	issues, err := models.GetMixedContentIssuesByURLID(urlID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch mixed content issues"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if issues == nil {
+		issues = []models.MixedContentIssue{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(issues),
+		"issues": issues,
+	})
+}
+
+// GetSecurityHeadersHandler handles GET /urls/:id/security-headers
+// Returns the stored response headers of the crawled page with their audit checks and grade
+func GetSecurityHeadersHandler(c *gin.Context) {
+	urlIDStr := c.Param("id")
+	urlID, err := strconv.Atoi(urlIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	audit, err := models.GetSecurityAuditByURLID(urlID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No security audit for this URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
+
+// GetCertificateHandler handles GET /urls/:id/certificate
+// Returns the TLS certificate chain and connection details recorded for an HTTPS URL
+func GetCertificateHandler(c *gin.Context) {
+	urlIDStr := c.Param("id")
+	urlID, err := strconv.Atoi(urlIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	cert, err := models.GetCertificateByURLID(urlID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No certificate for this URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
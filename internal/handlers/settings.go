@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CrawlSettingsResponse describes the configured credentials of a URL without revealing secrets
+type CrawlSettingsResponse struct {
+	HasBasicAuth   bool     `json:"has_basic_auth"`
+	BasicUser      string   `json:"basic_user,omitempty"`
+	HasBearerToken bool     `json:"has_bearer_token"`
+	HeaderNames    []string `json:"header_names"`
+	CookieNames    []string `json:"cookie_names"`
+	HasLogin       bool     `json:"has_login"`
+	LoginURL       string   `json:"login_url,omitempty"`
+}
+
+// GetCrawlSettingsHandler handles GET /admin/urls/:id/settings
+// Returns which credentials are configured for the URL; secret values are never returned
+func GetCrawlSettingsHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	creds, err := models.GetCrawlCredentials(urlID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load crawl settings"})
+		return
+	}
+
+	resp := CrawlSettingsResponse{HeaderNames: []string{}, CookieNames: []string{}}
+	if creds != nil {
+		resp.HasBasicAuth = creds.BasicUser != ""
+		resp.BasicUser = creds.BasicUser
+		resp.HasBearerToken = creds.BearerToken != ""
+		for name := range creds.Headers {
+			resp.HeaderNames = append(resp.HeaderNames, name)
+		}
+		for _, cookie := range creds.Cookies {
+			resp.CookieNames = append(resp.CookieNames, cookie.Name)
+		}
+		if creds.Login != nil {
+			resp.HasLogin = true
+			resp.LoginURL = creds.Login.LoginURL
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateCrawlSettingsHandler handles PUT /admin/urls/:id/settings
+// Replaces the credentials used when crawling the URL, storing them encrypted
+func UpdateCrawlSettingsHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req models.CrawlCredentials
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if req.BasicPass != "" && req.BasicUser == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "basic_user is required with basic_pass"})
+		return
+	}
+	for _, cookie := range req.Cookies {
+		if cookie.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cookie name is required"})
+			return
+		}
+	}
+
+	if login := req.Login; login != nil {
+		if !urlRegex.MatchString(login.LoginURL) || !regexp.MustCompile(`^https?://`).MatchString(login.LoginURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login URL"})
+			return
+		}
+		if login.UsernameField == "" || login.PasswordField == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username_field and password_field are required for login"})
+			return
+		}
+	}
+
+	if _, err := models.GetURLByID(urlID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	if err := models.SaveCrawlCredentials(urlID, req); err != nil {
+		if errors.Is(err, auth.ErrEncryptionKeyNotSet) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Crawl credentials are disabled: encryption key not configured"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save crawl settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Crawl settings saved"})
+}
+
+// DeleteCrawlSettingsHandler handles DELETE /admin/urls/:id/settings
+// Removes all credentials stored for the URL
+func DeleteCrawlSettingsHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	if err := models.DeleteCrawlCredentials(urlID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete crawl settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Crawl settings deleted"})
+}
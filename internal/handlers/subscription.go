@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/models"
+	"urlcrawler/internal/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscribeRequest sets how often the user is emailed about a URL; immediate if omitted
+type SubscribeRequest struct {
+	Frequency models.NotificationFrequency `json:"frequency"`
+}
+
+// GetSubscriptionsHandler handles GET /subscriptions
+// Returns the current user's email subscriptions
+func GetSubscriptionsHandler(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	subs, err := models.GetEmailSubscriptionsByUserID(userClaims.(*auth.CustomClaims).UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subscriptions"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if subs == nil {
+		subs = []models.EmailSubscription{}
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// SubscribeHandler handles PUT /urls/:id/subscription
+// Subscribes the current user to emails about new broken links and failed crawls of the URL
+func SubscribeHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	switch req.Frequency {
+	case "":
+		req.Frequency = models.NotifyImmediately
+	case models.NotifyImmediately, models.NotifyDaily, models.NotifyWeekly:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Frequency must be immediate, daily or weekly"})
+		return
+	}
+
+	if !notify.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email notifications are disabled: SMTP not configured"})
+		return
+	}
+
+	if _, err := models.GetURLByID(urlID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	userClaims, _ := c.Get("user")
+	sub := models.EmailSubscription{
+		UserID:       userClaims.(*auth.CustomClaims).UserID,
+		URLID:        urlID,
+		Frequency:    req.Frequency,
+		NextDigestAt: notify.NextDigest(req.Frequency, time.Now()),
+	}
+	if err := models.SaveEmailSubscription(&sub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// UnsubscribeHandler handles DELETE /urls/:id/subscription
+// Stops emails to the current user about the URL, discarding any pending digest
+func UnsubscribeHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	userClaims, _ := c.Get("user")
+	if err := models.DeleteEmailSubscription(userClaims.(*auth.CustomClaims).UserID, urlID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed"})
+}
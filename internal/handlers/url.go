@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"urlcrawler/internal/auth"
@@ -19,6 +19,12 @@ type AddURLRequest struct {
 	URL string `json:"url"`
 }
 
+// Request body for updating the crawl options of a URL; omitted fields are left unchanged
+type UpdateURLRequest struct {
+	VolatileSelectors *string `json:"volatile_selectors"`
+	Schedule          *string `json:"schedule"` // Cron expression or interval such as "6h"; empty string unschedules
+}
+
 // Basic URL validation regex - supports optional http(s), domain, and optional path
 var urlRegex = regexp.MustCompile(`^(https?://)?([a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}(/.*)?$`)
 
@@ -66,8 +72,40 @@ func AddURLHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, url)
 }
 
-// StartURLProcessingHandler starts asynchronous crawling for multiple URLs if not already processing
+// StartURLProcessingHandler queues multiple URLs for crawling by the worker pool if not already processing
 func StartURLProcessingHandler(c *gin.Context) {
+	var req struct {
+		URLIDs   []int  `json:"url_ids"`
+		WARC     bool   `json:"warc"`     // Archive the crawl's requests and responses as WARC
+		Priority string `json:"priority"` // low, normal (default), high or urgent
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	priority, err := crawler.ParsePriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claims := userClaims.(*auth.CustomClaims)
+
+	started, skipped := startURLs(req.URLIDs, claims.UserID, priority, crawler.Options{WARC: req.WARC})
+
+	c.JSON(http.StatusOK, gin.H{
+		"started_urls": started,
+		"skipped_urls": skipped,
+	})
+}
+
+// StopURLProcessingHandler attempts to stop currently processing URL tasks gracefully
+func StopURLProcessingHandler(c *gin.Context) {
 	var req struct {
 		URLIDs []int `json:"url_ids"`
 	}
@@ -76,10 +114,20 @@ func StartURLProcessingHandler(c *gin.Context) {
 		return
 	}
 
+	stopped, skipped := stopURLs(req.URLIDs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"stopped_urls": stopped,
+		"skipped_urls": skipped,
+	})
+}
+
+// startURLs queues the given URLs for crawling, skipping those already being processed
+func startURLs(urlIDs []int, userID int, priority crawler.Priority, opts crawler.Options) ([]int, map[int]string) {
 	started := []int{}
 	skipped := map[int]string{}
 
-	for _, id := range req.URLIDs {
+	for _, id := range urlIDs {
 		urlRecord, err := models.GetURLByID(id)
 		if err != nil {
 			skipped[id] = "URL not found"
@@ -91,39 +139,55 @@ func StartURLProcessingHandler(c *gin.Context) {
 			continue
 		}
 
-		if _, exists := crawler.GetTask(id); exists {
+		// Queue URL for the worker pool; a URL already queued or running is not queued twice
+		job := crawler.Job{
+			URLID:    id,
+			UserID:   userID,
+			Priority: priority,
+			Options:  opts,
+		}
+		if !crawler.Enqueue(job) {
 			skipped[id] = "Already being processed"
 			continue
 		}
 
 		started = append(started, id)
+	}
 
-		// Launch goroutine to process URL asynchronously
-		go func(urlID int) {
-			ctx, cancel := context.WithCancel(context.Background())
-			crawler.RegisterTask(urlID, cancel)
+	return started, skipped
+}
 
-			models.UpdateURLStatus(urlID, models.URLStatusProcessing)
+// stopURLs cancels the queued or running crawls of the given URLs
+func stopURLs(urlIDs []int) ([]int, map[int]string) {
+	stopped := []int{}
+	skipped := map[int]string{}
 
-			err := crawler.ProcessURL(ctx, urlID)
-			if err != nil {
-				models.UpdateURLStatusWithError(urlID, models.URLStatusError, err.Error())
-			} else {
-				models.UpdateURLStatus(urlID, models.URLStatusDone)
-			}
+	for _, id := range urlIDs {
+		urlRecord, err := models.GetURLByID(id)
+		if err != nil {
+			skipped[id] = "URL not found"
+			continue
+		}
 
-			crawler.UnregisterTask(urlID)
-		}(id)
+		if urlRecord.Status != models.URLStatusProcessing && urlRecord.Status != models.URLStatusQueued {
+			skipped[id] = "Not in processing state"
+			continue
+		}
+
+		if crawler.CancelTask(id) {
+			models.UpdateURLStatus(id, models.URLStatusStopped)
+			stopped = append(stopped, id)
+		} else {
+			skipped[id] = "Task not found or already stopped"
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"started_urls": started,
-		"skipped_urls": skipped,
-	})
+	return stopped, skipped
 }
 
-// StopURLProcessingHandler attempts to stop currently processing URL tasks gracefully
-func StopURLProcessingHandler(c *gin.Context) {
+// PauseURLProcessingHandler pauses queued or processing URLs; running crawls save
+// the links they have not checked yet so they can be resumed later
+func PauseURLProcessingHandler(c *gin.Context) {
 	var req struct {
 		URLIDs []int `json:"url_ids"`
 	}
@@ -132,7 +196,7 @@ func StopURLProcessingHandler(c *gin.Context) {
 		return
 	}
 
-	stopped := []int{}
+	paused := []int{}
 	skipped := map[int]string{}
 
 	for _, id := range req.URLIDs {
@@ -142,25 +206,146 @@ func StopURLProcessingHandler(c *gin.Context) {
 			continue
 		}
 
-		if urlRecord.Status != models.URLStatusProcessing {
+		if urlRecord.Status != models.URLStatusProcessing && urlRecord.Status != models.URLStatusQueued {
 			skipped[id] = "Not in processing state"
 			continue
 		}
 
-		if crawler.CancelTask(id) {
-			models.UpdateURLStatus(id, models.URLStatusStopped)
-			stopped = append(stopped, id)
+		if crawler.PauseTask(id) {
+			models.UpdateURLStatus(id, models.URLStatusPaused)
+			paused = append(paused, id)
 		} else {
 			skipped[id] = "Task not found or already stopped"
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"stopped_urls": stopped,
+		"paused_urls":  paused,
+		"skipped_urls": skipped,
+	})
+}
+
+// ResumeURLProcessingHandler queues paused URLs again, and URLs whose resume failed early;
+// each crawl continues from its checkpoint, or starts over if it was paused before any link was checked
+func ResumeURLProcessingHandler(c *gin.Context) {
+	var req struct {
+		URLIDs   []int  `json:"url_ids"`
+		Priority string `json:"priority"` // low, normal (default), high or urgent
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	priority, err := crawler.ParsePriority(req.Priority)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	claims := userClaims.(*auth.CustomClaims)
+
+	resumed := []int{}
+	skipped := map[int]string{}
+
+	for _, id := range req.URLIDs {
+		urlRecord, err := models.GetURLByID(id)
+		if err != nil {
+			skipped[id] = "URL not found"
+			continue
+		}
+
+		if urlRecord.Status != models.URLStatusPaused && !hasFailedResume(urlRecord) {
+			skipped[id] = "Not paused"
+			continue
+		}
+
+		job := crawler.Job{
+			URLID:    id,
+			UserID:   claims.UserID,
+			Priority: priority,
+			Options:  crawler.Options{Resume: true},
+		}
+		if !crawler.Enqueue(job) {
+			skipped[id] = "Already being processed"
+			continue
+		}
+
+		resumed = append(resumed, id)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resumed_urls": resumed,
 		"skipped_urls": skipped,
 	})
 }
 
+// hasFailedResume reports whether a resumed crawl of the URL failed before checking
+// any link, leaving its checkpoint behind to be resumed again.
+func hasFailedResume(u *models.URL) bool {
+	if u.Status != models.URLStatusError {
+		return false
+	}
+	checkpoint, err := models.GetCrawlCheckpoint(u.ID)
+	return err == nil && checkpoint != nil
+}
+
+// UpdateURLHandler updates the crawl options of a URL
+func UpdateURLHandler(c *gin.Context) {
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	urlRecord, err := models.GetURLByID(urlID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	if req.Schedule != nil {
+		schedule := strings.TrimSpace(*req.Schedule)
+		if schedule != "" {
+			if _, err := crawler.ParseSchedule(schedule); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule: " + err.Error()})
+				return
+			}
+		}
+		urlRecord.Schedule = schedule
+		urlRecord.NextRunAt = crawler.NextRun(schedule, time.Now())
+	}
+	if req.VolatileSelectors != nil {
+		urlRecord.VolatileSelectors = *req.VolatileSelectors
+	}
+
+	// Only the changed columns are written: a crawl may finish while this request runs
+	if req.Schedule != nil {
+		if err := models.UpdateURLSchedule(urlID, urlRecord.Schedule, urlRecord.NextRunAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update URL"})
+			return
+		}
+	}
+	if req.VolatileSelectors != nil {
+		if err := models.UpdateURLVolatileSelectors(urlID, urlRecord.VolatileSelectors); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update URL"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, urlRecord)
+}
+
 // GetURLsHandler returns all URLs in the system
 func GetURLsHandler(c *gin.Context) {
 	urls, err := models.GetAllURLs()
@@ -189,3 +374,37 @@ func DeleteURLHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "URL deleted successfully"})
 }
+
+// ScheduledURL describes the crawl schedule of a URL
+type ScheduledURL struct {
+	ID        int              `json:"id"`
+	URL       string           `json:"url"`
+	Schedule  string           `json:"schedule"`
+	NextRunAt *time.Time       `json:"next_run_at"`
+	LastRunAt *time.Time       `json:"last_run_at"`
+	Status    models.URLStatus `json:"status"`
+}
+
+// GetSchedulesHandler handles GET /admin/schedules
+// Returns every scheduled URL with its next and last scheduled run, soonest due first
+func GetSchedulesHandler(c *gin.Context) {
+	urls, err := models.GetScheduledURLs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedules"})
+		return
+	}
+
+	schedules := []ScheduledURL{}
+	for _, u := range urls {
+		schedules = append(schedules, ScheduledURL{
+			ID:        u.ID,
+			URL:       u.URL,
+			Schedule:  u.Schedule,
+			NextRunAt: u.NextRunAt,
+			LastRunAt: u.LastRunAt,
+			Status:    u.Status,
+		})
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
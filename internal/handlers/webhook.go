@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/models"
+	"urlcrawler/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhookRequest registers an endpoint for the given events.
+// A signing secret is generated if none is provided.
+type CreateWebhookRequest struct {
+	URL    string                `json:"url" binding:"required"`
+	Events []models.WebhookEvent `json:"events" binding:"required"`
+	Secret string                `json:"secret"`
+}
+
+// CreateWebhookResponse is the registered webhook along with its signing secret,
+// which is only ever returned here.
+type CreateWebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// isWebhookURL reports whether raw is an absolute http or https URL. Ports and IP
+// hosts are allowed so receivers on internal networks can be registered.
+func isWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != ""
+}
+
+// CreateWebhookHandler handles POST /admin/webhooks
+// Registers a webhook notified of the selected crawl events with HMAC-SHA256 signed payloads
+func CreateWebhookHandler(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if !isWebhookURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook URL"})
+		return
+	}
+	if len(req.Events) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one event is required"})
+		return
+	}
+	for _, event := range req.Events {
+		if !isWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + string(event)})
+			return
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhooks.GenerateSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+	}
+
+	userClaims, _ := c.Get("user")
+	webhook := models.Webhook{
+		URL:       req.URL,
+		Events:    req.Events,
+		Active:    true,
+		CreatedBy: userClaims.(*auth.CustomClaims).UserID,
+	}
+	if err := models.InsertWebhook(&webhook, secret); err != nil {
+		if errors.Is(err, auth.ErrEncryptionKeyNotSet) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhooks are disabled: encryption key not configured"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: webhook, Secret: secret})
+}
+
+func isWebhookEvent(event models.WebhookEvent) bool {
+	for _, e := range models.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWebhooksHandler handles GET /admin/webhooks
+// Returns all registered webhooks without their secrets
+func GetWebhooksHandler(c *gin.Context) {
+	list, err := models.GetWebhooks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if list == nil {
+		list = []models.Webhook{}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// DeleteWebhookHandler handles DELETE /admin/webhooks/:id
+// Removes a webhook and its delivery log
+func DeleteWebhookHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := models.DeleteWebhook(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GetWebhookDeliveriesHandler handles GET /admin/webhooks/:id/deliveries
+// Returns the latest deliveries of a webhook with their payloads and outcome, most recent first
+func GetWebhookDeliveriesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	deliveries, err := models.GetWebhookDeliveries(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook deliveries"})
+		return
+	}
+
+	// Return empty slice instead of null to ensure consistent JSON response
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/events"
+	"urlcrawler/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = wsPongTimeout * 9 / 10 // Must be shorter than the pong timeout
+	wsMaxMessage   = 64 * 1024
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return middleware.IsAllowedOrigin(r.Header.Get("Origin"))
+	},
+}
+
+// wsCommand is a message sent by the client.
+//
+//	{"action": "subscribe", "url_ids": [1, 2]}    receive events of these URLs; empty url_ids means all URLs
+//	{"action": "unsubscribe", "url_ids": [1]}     stop receiving events of these URLs; empty url_ids means all
+//	{"action": "start", "url_ids": [1], "priority": "high", "warc": false}  admins only
+//	{"action": "stop", "url_ids": [1]}                                     admins only
+type wsCommand struct {
+	ID       string `json:"id,omitempty"` // Echoed in the reply so clients can match it to the command
+	Action   string `json:"action"`
+	URLIDs   []int  `json:"url_ids"`
+	Priority string `json:"priority"`
+	WARC     bool   `json:"warc"`
+}
+
+// wsReply answers a command; events are sent as events.Event.
+type wsReply struct {
+	Type    string         `json:"type"` // "result" or "error"
+	ID      string         `json:"id,omitempty"`
+	Action  string         `json:"action,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	URLIDs  []int          `json:"url_ids,omitempty"` // URLs subscribed to, started or stopped
+	Skipped map[int]string `json:"skipped_urls,omitempty"`
+}
+
+// wsClient is an open WebSocket connection with the URLs it is subscribed to.
+type wsClient struct {
+	conn   *websocket.Conn
+	claims *auth.CustomClaims
+	sendMu sync.Mutex
+
+	mu     sync.Mutex
+	all    bool
+	urlIDs map[int]bool
+}
+
+// WebSocketHandler handles GET /ws
+// Upgrades to a WebSocket over which clients subscribe to status and progress events
+// of selected URLs and, if they are admins, start and stop crawls
+func WebSocketHandler(c *gin.Context) {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // The upgrader already replied with an HTTP error
+	}
+	defer conn.Close()
+
+	client := &wsClient{
+		conn:   conn,
+		claims: userClaims.(*auth.CustomClaims),
+		urlIDs: map[int]bool{},
+	}
+
+	sub := events.Subscribe(0)
+	defer sub.Close()
+
+	done := make(chan struct{})
+	go client.writeLoop(sub, done)
+	client.readLoop()
+	close(done)
+}
+
+// readLoop handles commands until the connection is closed.
+func (w *wsClient) readLoop() {
+	w.conn.SetReadLimit(wsMaxMessage)
+	w.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	w.conn.SetPongHandler(func(string) error {
+		return w.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, message, err := w.conn.ReadMessage()
+		if err != nil {
+			return // Closed by the client, or no pong in time
+		}
+
+		var cmd wsCommand
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			w.send(wsReply{Type: "error", Error: "Invalid message"})
+			continue
+		}
+		w.send(w.handle(cmd))
+	}
+}
+
+// handle runs a command and returns its reply, applying the admin group's permission check to crawl control.
+func (w *wsClient) handle(cmd wsCommand) wsReply {
+	reply := wsReply{Type: "result", ID: cmd.ID, Action: cmd.Action}
+
+	switch cmd.Action {
+	case "subscribe":
+		w.mu.Lock()
+		if len(cmd.URLIDs) == 0 {
+			w.all = true
+		}
+		for _, id := range cmd.URLIDs {
+			w.urlIDs[id] = true
+		}
+		w.mu.Unlock()
+		reply.URLIDs = cmd.URLIDs
+
+	case "unsubscribe":
+		w.mu.Lock()
+		if len(cmd.URLIDs) == 0 {
+			w.all = false
+			w.urlIDs = map[int]bool{}
+		}
+		for _, id := range cmd.URLIDs {
+			delete(w.urlIDs, id)
+		}
+		w.mu.Unlock()
+		reply.URLIDs = cmd.URLIDs
+
+	case "start", "stop":
+		if !w.claims.IsAdmin() {
+			return wsReply{Type: "error", ID: cmd.ID, Action: cmd.Action, Error: "Admins only"}
+		}
+		if cmd.Action == "stop" {
+			reply.URLIDs, reply.Skipped = stopURLs(cmd.URLIDs)
+			break
+		}
+		priority, err := crawler.ParsePriority(cmd.Priority)
+		if err != nil {
+			return wsReply{Type: "error", ID: cmd.ID, Action: cmd.Action, Error: "Invalid priority"}
+		}
+		reply.URLIDs, reply.Skipped = startURLs(cmd.URLIDs, w.claims.UserID, priority, crawler.Options{WARC: cmd.WARC})
+
+	default:
+		return wsReply{Type: "error", ID: cmd.ID, Action: cmd.Action, Error: "Unknown action"}
+	}
+
+	return reply
+}
+
+// writeLoop forwards the events of subscribed URLs and keeps the connection alive with pings.
+func (w *wsClient) writeLoop(sub *events.Subscription, done <-chan struct{}) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case e := <-sub.C:
+			if w.subscribed(e.URLID) {
+				w.send(e)
+			}
+		case <-ping.C:
+			w.sendMu.Lock()
+			err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+			w.sendMu.Unlock()
+			if err != nil {
+				w.conn.Close() // Unblocks the read loop
+				return
+			}
+		}
+	}
+}
+
+func (w *wsClient) subscribed(urlID int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.all || w.urlIDs[urlID]
+}
+
+// send writes a JSON message; gorilla/websocket allows only one concurrent writer.
+func (w *wsClient) send(v interface{}) {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	w.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	w.conn.WriteJSON(v)
+}
@@ -20,13 +20,26 @@ func getAllowedOrigins() []string {
     return origins
 }
 
+// IsAllowedOrigin reports whether an Origin header value is one of the allowed CORS origins.
+// Requests without an Origin header do not come from a browser and are allowed.
+func IsAllowedOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range getAllowedOrigins() {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
 
 // CORSMiddleware configures and returns a CORS middleware handler for Gin.
 // It allows specified HTTP methods, headers, credentials, and caches options preflight for 12 hours.
 func CORSMiddleware() gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowOrigins:     getAllowedOrigins(),
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
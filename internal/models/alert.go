@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"time"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// AlertKind defines the condition an alert rule checks after each crawl.
+type AlertKind string
+
+const (
+	AlertBrokenLinks  AlertKind = "broken_links"  // More broken links than the threshold
+	AlertStatusError  AlertKind = "status_error"  // The crawl failed
+	AlertTitleChanged AlertKind = "title_changed" // The page title differs from the previous crawl
+	AlertResponseTime AlertKind = "response_time" // The page fetch took longer than the threshold in milliseconds
+	AlertCertExpiry   AlertKind = "cert_expiry"   // The certificate expires in fewer days than the threshold
+)
+
+// AlertKinds lists every kind of alert rule.
+var AlertKinds = []AlertKind{AlertBrokenLinks, AlertStatusError, AlertTitleChanged, AlertResponseTime, AlertCertExpiry}
+
+// HasThreshold reports whether rules of the kind compare against a threshold.
+func (k AlertKind) HasThreshold() bool {
+	return k == AlertBrokenLinks || k == AlertResponseTime || k == AlertCertExpiry
+}
+
+// Describe returns the condition of a rule in words, e.g. "broken links > 5".
+func (k AlertKind) Describe(threshold int) string {
+	switch k {
+	case AlertBrokenLinks:
+		return fmt.Sprintf("broken links > %d", threshold)
+	case AlertStatusError:
+		return "status changed to error"
+	case AlertTitleChanged:
+		return "title changed"
+	case AlertResponseTime:
+		return fmt.Sprintf("response time > %dms", threshold)
+	case AlertCertExpiry:
+		return fmt.Sprintf("certificate expires in < %d days", threshold)
+	default:
+		return string(k)
+	}
+}
+
+// AlertState defines whether an alert rule's condition currently holds.
+type AlertState string
+
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved" // Also the state of a rule that never fired
+)
+
+// AlertRule is a condition on the results of a URL's crawls. Its state is kept
+// between crawls so notifications are only sent when it starts or stops firing.
+type AlertRule struct {
+	ID              int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	URLID           int        `gorm:"not null;index" json:"url_id"`
+	Kind            AlertKind  `gorm:"not null" json:"kind"`
+	Threshold       int        `gorm:"not null" json:"threshold"`
+	Condition       string     `gorm:"-" json:"condition"`
+	State           AlertState `gorm:"not null;default:resolved" json:"state"`
+	Value           string     `json:"value"`   // Value observed by the last evaluation
+	Message         string     `json:"message"` // Explanation of the last evaluation
+	LastEvaluatedAt *time.Time `json:"last_evaluated_at"`
+	ChangedAt       *time.Time `json:"changed_at"` // When the rule last started or stopped firing
+	CreatedBy       int        `gorm:"not null" json:"created_by"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// AfterFind describes the condition of the rule for API consumers.
+func (r *AlertRule) AfterFind(tx *gorm.DB) error {
+	r.Condition = r.Kind.Describe(r.Threshold)
+	return nil
+}
+
+// InsertAlertRule stores a new alert rule.
+func InsertAlertRule(r *AlertRule) error {
+	if err := db.DB.Create(r).Error; err != nil {
+		return err
+	}
+	r.Condition = r.Kind.Describe(r.Threshold)
+	return nil
+}
+
+// GetAlertRulesByURLID returns the alert rules of a URL.
+func GetAlertRulesByURLID(urlID int) ([]AlertRule, error) {
+	var rules []AlertRule
+	err := db.DB.Where("url_id = ?", urlID).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// DeleteAlertRule deletes an alert rule.
+func DeleteAlertRule(id int) error {
+	result := db.DB.Delete(&AlertRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Alert rule with ID %d not found", id)
+	}
+	return nil
+}
+
+// UpdateAlertRuleEvaluation saves the outcome of evaluating a rule.
+func UpdateAlertRuleEvaluation(r *AlertRule) error {
+	return db.DB.Model(&AlertRule{}).
+		Where("id = ?", r.ID).
+		Updates(map[string]interface{}{
+			"state":             r.State,
+			"value":             r.Value,
+			"message":           r.Message,
+			"last_evaluated_at": r.LastEvaluatedAt,
+			"changed_at":        r.ChangedAt,
+		}).Error
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+	"urlcrawler/internal/db"
+)
+
+// ChainCertificate describes one certificate of the chain presented by the server.
+type ChainCertificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	SANs      []string  `json:"sans"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	KeyType   string    `json:"key_type"`
+}
+
+// Certificate holds the TLS connection details and certificate chain of an HTTPS URL.
+// Subject, issuer and validity are those of the leaf certificate.
+type Certificate struct {
+	ID            int                `gorm:"primaryKey;autoIncrement" json:"-"`
+	URLID         int                `gorm:"not null;uniqueIndex" json:"url_id"`
+	TLSVersion    string             `json:"tls_version"`
+	CipherSuite   string             `json:"cipher_suite"`
+	Subject       string             `json:"subject"`
+	Issuer        string             `json:"issuer"`
+	NotBefore     time.Time          `json:"not_before"`
+	NotAfter      time.Time          `json:"not_after"`
+	HostnameMatch bool               `gorm:"not null" json:"hostname_match"`
+	ExpiresSoon   bool               `gorm:"not null" json:"expires_soon"` // True if the leaf expires within the configured window
+	VerifyError   string             `json:"verify_error,omitempty"`     // Verification failure reported by the TLS handshake, if any
+	Chain         []ChainCertificate `gorm:"type:text;serializer:json" json:"chain"`
+	CreatedAt     time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// InsertCertificate inserts a new Certificate record into the database.
+func InsertCertificate(c Certificate) error {
+	return db.DB.Create(&c).Error
+}
+
+// DeleteCertificateByURLID deletes the certificate associated with a given URL ID.
+func DeleteCertificateByURLID(urlID int) error {
+	return db.DB.Where("url_id = ?", urlID).Delete(&Certificate{}).Error
+}
+
+// GetCertificateByURLID retrieves the certificate for a given URL ID.
+func GetCertificateByURLID(urlID int) (*Certificate, error) {
+	var c Certificate
+	err := db.DB.Where("url_id = ?", urlID).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// ErrCrawlPaused is wrapped by crawl errors caused by an admin pausing the crawl.
+var ErrCrawlPaused = errors.New("crawl paused")
+
+// FrontierItem is a link or sub-resource of the crawled page that has not been checked yet.
+type FrontierItem struct {
+	Href         string       `json:"href"`
+	ResourceType ResourceType `json:"resource_type"`
+}
+
+// CrawlCheckpoint is the saved progress of a paused crawl. Links checked before
+// the pause are already stored; Pending lists the ones left, in crawl order.
+// There is at most one checkpoint per URL.
+type CrawlCheckpoint struct {
+	ID           int            `gorm:"primaryKey;autoIncrement" json:"-"`
+	URLID        int            `gorm:"not null;uniqueIndex" json:"url_id"`
+	RunID        int            `gorm:"not null" json:"run_id"` // Paused run; its page snapshot is the document being analysed
+	StatusCode   int            `gorm:"not null" json:"status_code"`
+	ETag         string         `gorm:"column:etag" json:"-"`
+	LastModified string         `json:"-"`
+	Pending      []FrontierItem `gorm:"type:mediumtext;serializer:json" json:"pending"`
+	Checked      int            `gorm:"not null" json:"checked"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SaveCrawlCheckpoint stores the checkpoint of a URL, replacing any previous one.
+func SaveCrawlCheckpoint(c *CrawlCheckpoint) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("url_id = ?", c.URLID).Delete(&CrawlCheckpoint{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(c).Error
+	})
+}
+
+// GetCrawlCheckpoint returns the checkpoint of a URL, or nil if it has none.
+func GetCrawlCheckpoint(urlID int) (*CrawlCheckpoint, error) {
+	var checkpoints []CrawlCheckpoint
+	if err := db.DB.Where("url_id = ?", urlID).Limit(1).Find(&checkpoints).Error; err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	return &checkpoints[0], nil
+}
+
+// DeleteCrawlCheckpoint removes the checkpoint of a URL, if any.
+func DeleteCrawlCheckpoint(urlID int) error {
+	return db.DB.Where("url_id = ?", urlID).Delete(&CrawlCheckpoint{}).Error
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+	"urlcrawler/internal/db"
+	"urlcrawler/internal/events"
+)
+
+// CrawlEvent is a live event published by a worker process, kept briefly so the
+// API server can pass it on to its subscribers.
+type CrawlEvent struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	URLID     int       `gorm:"not null"`
+	Event     string    `gorm:"not null"` // events.Event as JSON
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+// InsertCrawlEvent stores an event for the API server.
+func InsertCrawlEvent(e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return db.DB.Create(&CrawlEvent{URLID: e.URLID, Event: string(data)}).Error
+}
+
+// Decode returns the stored event.
+func (e *CrawlEvent) Decode() (events.Event, error) {
+	var event events.Event
+	err := json.Unmarshal([]byte(e.Event), &event)
+	return event, err
+}
+
+// GetCrawlEventsAfter returns up to limit events stored after the event with ID afterID, oldest first.
+func GetCrawlEventsAfter(afterID, limit int) ([]CrawlEvent, error) {
+	var rows []CrawlEvent
+	err := db.DB.Where("id > ?", afterID).Order("id").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// GetLastCrawlEventID returns the ID of the newest stored event, or 0 if there is none.
+func GetLastCrawlEventID() (int, error) {
+	var id int
+	err := db.DB.Model(&CrawlEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// DeleteCrawlEventsBefore removes the events stored before t.
+func DeleteCrawlEventsBefore(t time.Time) error {
+	return db.DB.Where("created_at < ?", t).Delete(&CrawlEvent{}).Error
+}
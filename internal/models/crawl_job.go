@@ -0,0 +1,196 @@
+package models
+
+import (
+	"errors"
+	"time"
+	"urlcrawler/internal/db"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CrawlJobControl is a request from the API server to the worker running a job.
+type CrawlJobControl string
+
+const (
+	CrawlJobRun   CrawlJobControl = ""      // No request; keep crawling
+	CrawlJobStop  CrawlJobControl = "stop"  // Cancel the crawl
+	CrawlJobPause CrawlJobControl = "pause" // Checkpoint the crawl and stop
+)
+
+// CrawlJob is a crawl in the MySQL queue shared by worker processes. A URL has at
+// most one job, which is deleted once the crawl ends. A job is leased by the worker
+// running it until its lease expires; workers renew the lease with heartbeats, so
+// the job of a worker that died is picked up by another one.
+type CrawlJob struct {
+	ID             int             `gorm:"primaryKey;autoIncrement"`
+	URLID          int             `gorm:"not null;uniqueIndex"`
+	UserID         int             `gorm:"not null"`
+	Priority       int             `gorm:"not null"`
+	Options        string          `gorm:"type:text;not null"` // JSON encoded crawler options
+	Control        CrawlJobControl `gorm:"not null;default:''"`
+	WorkerID       string          `gorm:"not null;default:''"` // Empty while queued
+	LeaseExpiresAt *time.Time
+	HeartbeatAt    *time.Time
+	Attempts       int       `gorm:"not null"` // Number of times the job was leased
+	QueuedAt       time.Time `gorm:"autoCreateTime"`
+}
+
+// InsertCrawlJob queues a job. It returns false if the URL already has a job.
+func InsertCrawlJob(j *CrawlJob) (bool, error) {
+	err := db.DB.Create(j).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 { // Duplicate entry for url_id
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// leasableCrawlJob matches queued jobs and jobs whose lease expired.
+const leasableCrawlJob = "control = '' AND (worker_id = '' OR lease_expires_at < NOW())"
+
+// leaseCandidates is how many jobs a worker tries to lease in order, so workers
+// racing for the same job fall back to the next one instead of waiting.
+const leaseCandidates = 10
+
+// LeaseCrawlJob leases the next job to a worker: queued jobs and jobs whose lease
+// expired, highest priority first. Within a priority, users with fewer running
+// jobs go first so no single user fills every worker. Returns nil if no job is available.
+func LeaseCrawlJob(workerID string, lease time.Duration) (*CrawlJob, error) {
+	// Jobs are chosen without locking: the ordering sorts every leasable job, so a
+	// locking read would lock them all and concurrent workers would find none
+	var ids []int
+	err := db.DB.Model(&CrawlJob{}).
+		Where(leasableCrawlJob).
+		Order("priority DESC").
+		Order("(SELECT COUNT(*) FROM crawl_jobs AS running WHERE running.user_id = crawl_jobs.user_id AND running.worker_id <> '')").
+		Order("queued_at, id").
+		Limit(leaseCandidates).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the first candidate still leasable; the update locks only its row
+	for _, id := range ids {
+		result := db.DB.Model(&CrawlJob{}).
+			Where("id = ? AND "+leasableCrawlJob, id).
+			Updates(map[string]interface{}{
+				"worker_id":        workerID,
+				"lease_expires_at": gorm.Expr("NOW() + INTERVAL ? SECOND", int(lease.Seconds())),
+				"heartbeat_at":     gorm.Expr("NOW()"),
+				"attempts":         gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue // Leased by another worker, stopped or paused in the meantime
+		}
+
+		var jobs []CrawlJob
+		if err := db.DB.Where("id = ? AND worker_id = ?", id, workerID).Limit(1).Find(&jobs).Error; err != nil {
+			return nil, err
+		}
+		if len(jobs) > 0 {
+			return &jobs[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// RenewCrawlJobLease extends the lease of a worker's job and returns the pending
+// control request. It returns false if the job was deleted or leased by another worker.
+func RenewCrawlJobLease(id int, workerID string, lease time.Duration) (CrawlJobControl, bool, error) {
+	result := db.DB.Model(&CrawlJob{}).
+		Where("id = ? AND worker_id = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"lease_expires_at": gorm.Expr("NOW() + INTERVAL ? SECOND", int(lease.Seconds())),
+			"heartbeat_at":     gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return CrawlJobRun, false, result.Error
+	}
+
+	var jobs []CrawlJob
+	if err := db.DB.Where("id = ? AND worker_id = ?", id, workerID).Limit(1).Find(&jobs).Error; err != nil {
+		return CrawlJobRun, false, err
+	}
+	if len(jobs) == 0 {
+		return CrawlJobRun, false, nil
+	}
+	return jobs[0].Control, true, nil
+}
+
+// ControlCrawlJob asks for the job of a URL to be stopped or paused. A job still
+// queued is deleted right away; a running one is signalled to its worker through
+// the next heartbeat. Returns false if the URL has no job.
+func ControlCrawlJob(urlID int, control CrawlJobControl) (bool, error) {
+	found := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var jobs []CrawlJob
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("url_id = ? AND control = ''", urlID).
+			Limit(1).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		found = true
+		if jobs[0].WorkerID == "" {
+			return tx.Delete(&CrawlJob{}, jobs[0].ID).Error
+		}
+		return tx.Model(&CrawlJob{}).Where("id = ?", jobs[0].ID).Update("control", control).Error
+	})
+	return found && err == nil, err
+}
+
+// ReleaseCrawlJob puts a worker's job back in the queue for another worker.
+func ReleaseCrawlJob(id int, workerID string) error {
+	return db.DB.Model(&CrawlJob{}).
+		Where("id = ? AND worker_id = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"worker_id":        "",
+			"lease_expires_at": nil,
+			"attempts":         gorm.Expr("GREATEST(attempts - 1, 0)"), // An orderly release does not count
+		}).Error
+}
+
+// DeleteCrawlJob removes a finished job, unless it was leased by another worker in the meantime.
+func DeleteCrawlJob(id int, workerID string) error {
+	return db.DB.Where("id = ? AND worker_id = ?", id, workerID).Delete(&CrawlJob{}).Error
+}
+
+// ReapCrawlJobs deletes jobs that are no longer worth leasing and returns those that
+// must be recorded as failed: expired jobs already leased maxAttempts times, whose
+// crawls keep losing their worker. Expired jobs that were stopped or paused are
+// deleted silently, as the API server already set the status of their URL.
+func ReapCrawlJobs(maxAttempts int) ([]CrawlJob, error) {
+	var failed []CrawlJob
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("worker_id <> '' AND lease_expires_at < NOW() AND control <> ''").
+			Delete(&CrawlJob{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("worker_id <> '' AND lease_expires_at < NOW() AND attempts >= ?", maxAttempts).
+			Find(&failed).Error
+		if err != nil || len(failed) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(failed))
+		for i, job := range failed {
+			ids[i] = job.ID
+		}
+		return tx.Where("id IN ?", ids).Delete(&CrawlJob{}).Error
+	})
+	return failed, err
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// CrawlRunStatus defines possible statuses of a single crawl of a URL.
+type CrawlRunStatus string
+
+const (
+	CrawlRunRunning     CrawlRunStatus = "running"
+	CrawlRunDone        CrawlRunStatus = "done"
+	CrawlRunError       CrawlRunStatus = "error"
+	CrawlRunStopped     CrawlRunStatus = "stopped"
+	CrawlRunLoginFailed CrawlRunStatus = "login_failed"
+	CrawlRunPaused      CrawlRunStatus = "paused"
+)
+
+// ErrLoginFailed is wrapped by crawl errors caused by the URL's login recipe not authenticating.
+var ErrLoginFailed = errors.New("login failed")
+
+// CrawlRun records one crawl of a URL along with the performance and weight
+// metrics of the page fetch. Durations are in milliseconds, sizes in bytes.
+type CrawlRun struct {
+	ID              int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	URLID           int            `gorm:"not null;index" json:"url_id"`
+	Status          CrawlRunStatus `json:"status"`
+	ErrorMessage    string         `json:"error_message,omitempty"`
+	DNSMs           int64          `gorm:"column:dns_ms" json:"dns_ms"`
+	ConnectMs       int64          `json:"connect_ms"`
+	TLSMs           int64          `gorm:"column:tls_ms" json:"tls_ms"`
+	TTFBMs          int64          `gorm:"column:ttfb_ms" json:"ttfb_ms"`
+	DownloadMs      int64          `json:"download_ms"`
+	TotalMs         int64          `json:"total_ms"`
+	TransferSize    int64          `json:"transfer_size"`             // Bytes received on the wire (compressed)
+	ContentSize     int64          `json:"content_size"`              // Bytes of the decoded HTML document
+	ResourceCount   int            `json:"resource_count"`            // Number of sub-resources referenced by the page
+	PageWeight      int64          `json:"page_weight"`               // Transfer size plus the known size of all sub-resources
+	ContentHash     string         `json:"content_hash"`              // SHA-256 of the page HTML with volatile parts removed
+	ContentChanged  bool           `json:"content_changed"`           // True if the hash differs from the previous analysed run
+	AnalysisSkipped bool           `json:"analysis_skipped"`          // True if content was unchanged and the previous report was kept
+	NotModified     bool           `json:"not_modified"`              // True if the server answered the conditional request with 304
+	WARCPath        string         `gorm:"column:warc_path" json:"-"` // Local WARC archive of the run, if one was requested
+	HasWARC         bool           `gorm:"-" json:"has_warc"`
+	StartedAt       time.Time      `gorm:"autoCreateTime" json:"started_at"`
+	FinishedAt      *time.Time     `json:"finished_at"`
+}
+
+// AfterFind exposes whether a WARC archive exists without revealing its server path.
+func (r *CrawlRun) AfterFind(tx *gorm.DB) error {
+	r.HasWARC = r.WARCPath != ""
+	return nil
+}
+
+// StartCrawlRun creates a new running CrawlRun for a URL.
+func StartCrawlRun(urlID int) (*CrawlRun, error) {
+	run := &CrawlRun{
+		URLID:     urlID,
+		Status:    CrawlRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := db.DB.Create(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// FinishCrawlRun saves the run with its final status derived from the crawl error.
+// A cancelled context marks the run as stopped rather than failed, and a failed
+// login or a pause is kept apart from other errors.
+func FinishCrawlRun(run *CrawlRun, crawlErr error) error {
+	now := time.Now()
+	run.FinishedAt = &now
+
+	switch {
+	case crawlErr == nil:
+		run.Status = CrawlRunDone
+	case errors.Is(crawlErr, ErrCrawlPaused):
+		run.Status = CrawlRunPaused
+	case errors.Is(crawlErr, context.Canceled):
+		run.Status = CrawlRunStopped
+	case errors.Is(crawlErr, ErrLoginFailed):
+		run.Status = CrawlRunLoginFailed
+		run.ErrorMessage = crawlErr.Error()
+	default:
+		run.Status = CrawlRunError
+		run.ErrorMessage = crawlErr.Error()
+	}
+
+	return db.DB.Save(run).Error
+}
+
+// ResumeCrawlRun marks a paused run of a URL as running again.
+func ResumeCrawlRun(urlID, runID int) (*CrawlRun, error) {
+	run, err := GetCrawlRun(urlID, runID)
+	if err != nil {
+		return nil, err
+	}
+	run.Status = CrawlRunRunning
+	run.FinishedAt = nil
+	if err := db.DB.Save(run).Error; err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetPreviousAnalysedRun returns the latest run of a URL before the given run that
+// has a content hash, or nil if there is none. It is also nil if that run did not
+// complete, since an interrupted analysis may have left a partial report behind.
+func GetPreviousAnalysedRun(urlID, runID int) (*CrawlRun, error) {
+	var runs []CrawlRun
+	err := db.DB.
+		Where("url_id = ? AND id < ? AND content_hash <> ''", urlID, runID).
+		Order("id DESC").
+		Limit(1).
+		Find(&runs).Error
+	if err != nil || len(runs) == 0 || runs[0].Status != CrawlRunDone {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// GetCrawlRunsByURLID returns the crawl runs of a URL, most recent first.
+func GetCrawlRunsByURLID(urlID int) ([]CrawlRun, error) {
+	var runs []CrawlRun
+	err := db.DB.Where("url_id = ?", urlID).Order("started_at DESC, id DESC").Find(&runs).Error
+	return runs, err
+}
+
+// GetLatestCrawlRun returns the most recent crawl run of a URL, or nil if it was never crawled.
+func GetLatestCrawlRun(urlID int) (*CrawlRun, error) {
+	var runs []CrawlRun
+	err := db.DB.Where("url_id = ?", urlID).Order("started_at DESC, id DESC").Limit(1).Find(&runs).Error
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// GetCrawlRun retrieves a crawl run by ID, scoped to the URL it belongs to.
+func GetCrawlRun(urlID, runID int) (*CrawlRun, error) {
+	var run CrawlRun
+	err := db.DB.Where("id = ? AND url_id = ?", runID, urlID).First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
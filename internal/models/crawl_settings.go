@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// CrawlCookie is a cookie sent with requests to the host of the crawled URL.
+type CrawlCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Path  string `json:"path,omitempty"` // Defaults to "/"
+}
+
+// LoginRecipe describes how to sign in through a login form before crawling.
+// Every input of the form, including hidden CSRF tokens, is submitted with its
+// current value unless overridden by the username, password or extra fields.
+type LoginRecipe struct {
+	LoginURL      string            `json:"login_url"`
+	FormSelector  string            `json:"form_selector,omitempty"` // Defaults to the first form with a password input
+	UsernameField string            `json:"username_field"`
+	PasswordField string            `json:"password_field"`
+	Username      string            `json:"username"`
+	Password      string            `json:"password"`
+	ExtraFields   map[string]string `json:"extra_fields,omitempty"`
+	SuccessCookie string            `json:"success_cookie,omitempty"` // Cookie that must be set once logged in
+	SuccessText   string            `json:"success_text,omitempty"`   // Text the page after login must contain
+	FailureText   string            `json:"failure_text,omitempty"`   // Text that means the login was rejected
+}
+
+// CrawlCredentials holds the authentication applied when crawling a URL.
+// They are only ever sent to the host of the crawled URL.
+type CrawlCredentials struct {
+	BasicUser   string            `json:"basic_user,omitempty"`
+	BasicPass   string            `json:"basic_pass,omitempty"`
+	BearerToken string            `json:"bearer_token,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Cookies     []CrawlCookie     `json:"cookies,omitempty"`
+	Login       *LoginRecipe      `json:"login,omitempty"` // Run before the page fetch; may sign in on another host
+}
+
+// CrawlSettings stores the per-URL crawl credentials, encrypted at rest.
+type CrawlSettings struct {
+	ID                   int       `gorm:"primaryKey;autoIncrement"`
+	URLID                int       `gorm:"not null;uniqueIndex"`
+	EncryptedCredentials string    `gorm:"not null"`
+	CreatedAt            time.Time `gorm:"autoCreateTime"`
+	UpdatedAt            time.Time `gorm:"autoUpdateTime"`
+}
+
+// SaveCrawlCredentials encrypts and stores the credentials of a URL, replacing any existing ones.
+func SaveCrawlCredentials(urlID int, creds CrawlCredentials) error {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	encrypted, err := auth.EncryptSecret(plaintext)
+	if err != nil {
+		return err
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("url_id = ?", urlID).Delete(&CrawlSettings{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&CrawlSettings{URLID: urlID, EncryptedCredentials: encrypted}).Error
+	})
+}
+
+// GetCrawlCredentials loads and decrypts the credentials of a URL.
+// Returns nil without error if the URL has none.
+func GetCrawlCredentials(urlID int) (*CrawlCredentials, error) {
+	var settings CrawlSettings
+	err := db.DB.Where("url_id = ?", urlID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := auth.DecryptSecret(settings.EncryptedCredentials)
+	if err != nil {
+		return nil, err
+	}
+	var creds CrawlCredentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// DeleteCrawlCredentials removes the stored credentials of a URL.
+func DeleteCrawlCredentials(urlID int) error {
+	return db.DB.Where("url_id = ?", urlID).Delete(&CrawlSettings{}).Error
+}
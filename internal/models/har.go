@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// CrawlRunHAR stores the gzip-compressed HTTP Archive of every request made during a crawl run.
+type CrawlRunHAR struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	RunID     int       `gorm:"not null;uniqueIndex"`
+	URLID     int       `gorm:"not null;index"`
+	HAR       []byte    `gorm:"column:har;not null"` // gzip compressed HAR 1.2 JSON
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName keeps the table name readable; the default would be "crawl_run_h_a_rs".
+func (CrawlRunHAR) TableName() string {
+	return "crawl_run_hars"
+}
+
+// SaveCrawlRunHAR compresses and stores the HAR of a crawl run, replacing the one
+// saved when the run was paused, if any.
+func SaveCrawlRunHAR(urlID, runID int, har []byte) error {
+	compressed, err := gzipBytes(har)
+	if err != nil {
+		return err
+	}
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("run_id = ?", runID).Delete(&CrawlRunHAR{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&CrawlRunHAR{RunID: runID, URLID: urlID, HAR: compressed}).Error
+	})
+}
+
+// GetCrawlRunHAR returns the decompressed HAR JSON stored for a crawl run of a URL.
+func GetCrawlRunHAR(urlID, runID int) ([]byte, error) {
+	var har CrawlRunHAR
+	if err := db.DB.Where("run_id = ? AND url_id = ?", runID, urlID).First(&har).Error; err != nil {
+		return nil, err
+	}
+	return gunzipBytes(har.HAR)
+}
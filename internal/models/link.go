@@ -2,14 +2,56 @@ package models
 
 import "urlcrawler/internal/db"
 
-// Link represents a link found on a crawled URL.
+// ResourceType defines the kind of reference a Link record was extracted from.
+type ResourceType string
+
+const (
+	ResourceLink       ResourceType = "link"       // <a href>
+	ResourceScript     ResourceType = "script"     // <script src>
+	ResourceStylesheet ResourceType = "stylesheet" // <link rel=stylesheet>
+	ResourceFont       ResourceType = "font"       // <link rel=preload as=font>
+	ResourceIframe     ResourceType = "iframe"     // <iframe src>
+	ResourceVideo      ResourceType = "video"      // <video src>, <video><source src>
+	ResourceAudio      ResourceType = "audio"      // <audio src>, <audio><source src>
+	ResourceFavicon    ResourceType = "favicon"    // <link rel=icon>
+)
+
+// LinkKind classifies a link by its URL scheme.
+type LinkKind string
+
+const (
+	LinkKindHTTP       LinkKind = "http" // http:// and https:// links, checked over the network
+	LinkKindMailto     LinkKind = "mailto"
+	LinkKindTel        LinkKind = "tel"
+	LinkKindJavaScript LinkKind = "javascript"
+	LinkKindData       LinkKind = "data"
+	LinkKindOther      LinkKind = "other" // Any other scheme, e.g. ftp: or app-specific schemes
+)
+
+// LinkIssue flags a problem with a link that its status code alone does not show.
+type LinkIssue string
+
+const (
+	LinkIssueNone           LinkIssue = ""
+	LinkIssueDanglingAnchor LinkIssue = "dangling_anchor" // Fragment does not match any id or <a name> in the target
+	LinkIssueInvalidSyntax  LinkIssue = "invalid_syntax"  // mailto: or tel: link with a malformed address or number
+	LinkIssueSoft404        LinkIssue = "soft_404"        // Internal page answering 200 with "not found" content
+)
+
+// Link represents a link or sub-resource found on a crawled URL.
 type Link struct {
-	ID         int    `gorm:"primaryKey;autoIncrement"`
-	URLID      int    `gorm:"not null;index"`
-	Href       string `gorm:"not null"`
+	ID           int          `gorm:"primaryKey;autoIncrement"`
+	URLID        int          `gorm:"not null;index"`
+	Href         string       `gorm:"not null"`
+	ResourceType ResourceType `gorm:"not null;default:link"` // Element type the href was taken from
+	Kind         LinkKind     `gorm:"not null;default:http"` // Scheme class; only http links are checked over the network
 	IsInternal bool `gorm:"not null"`			 // Indicates if the link is internal to the base URL's domain
-	StatusCode int    `gorm:"default:0"`         // HTTP status code returned when checking the link; 0 means not checked yet
+	StatusCode int    `gorm:"default:0"`         // HTTP status code returned when checking the link; 0 means not checked or not an http link
 	IsBroken   bool   `gorm:"default:false"`     // True if the link is identified as broken
+	Issue      LinkIssue `gorm:"not null;default:''"` // Problem detected beyond the status code, if any
+	CheckMethod string   `gorm:"not null;default:''"` // HTTP method that produced the recorded status; empty if not checked
+	Attempts    int      `gorm:"not null;default:0"`  // Requests sent with CheckMethod, including retries of transient failures
+	IsFlaky     bool     `gorm:"not null;default:false"` // True if the link only succeeded after retries
 }
 
 // InsertLink inserts a new Link record into the database.
@@ -22,51 +64,90 @@ func DeleteLinksByURLID(urlID int) error {
 	return db.DB.Where("url_id = ?", urlID).Delete(&Link{}).Error
 }
 
-// LinkCount holds counts of internal and external links for a URL.
+// LinkCount holds counts of internal and external links for a URL,
+// along with the number of sub-resources and broken or flaky entries of any type.
 type LinkCount struct {
-	Internal int64 `json:"internal"`
-	External int64 `json:"external"`
+	Internal  int64 `json:"internal"`
+	External  int64 `json:"external"`
+	Resources int64 `json:"resources"`
+	Broken    int64 `json:"broken"`
+	Flaky     int64 `json:"flaky"`
 }
 
 // GetLinkCountByURLID returns the count of internal and external links for a given URL ID.
+// Only anchor links are split by internal/external; sub-resources are counted separately.
 func GetLinkCountByURLID(urlID int) (*LinkCount, error) {
 	var internalCount int64
 	var externalCount int64
+	var resourceCount int64
+	var brokenCount int64
+	var flakyCount int64
 
 	if err := db.DB.
 		Model(&Link{}).
-		Where("url_id = ? AND is_internal = true", urlID).
+		Where("url_id = ? AND resource_type = ? AND is_internal = true", urlID, ResourceLink).
 		Count(&internalCount).Error; err != nil {
 		return nil, err
 	}
 
 	if err := db.DB.
 		Model(&Link{}).
-		Where("url_id = ? AND is_internal = false", urlID).
+		Where("url_id = ? AND resource_type = ? AND is_internal = false", urlID, ResourceLink).
 		Count(&externalCount).Error; err != nil {
 		return nil, err
 	}
 
+	if err := db.DB.
+		Model(&Link{}).
+		Where("url_id = ? AND resource_type <> ?", urlID, ResourceLink).
+		Count(&resourceCount).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.
+		Model(&Link{}).
+		Where("url_id = ? AND is_broken = true", urlID).
+		Count(&brokenCount).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.DB.
+		Model(&Link{}).
+		Where("url_id = ? AND is_flaky = true", urlID).
+		Count(&flakyCount).Error; err != nil {
+		return nil, err
+	}
+
 	return &LinkCount{
-		Internal: internalCount,
-		External: externalCount,
+		Internal:  internalCount,
+		External:  externalCount,
+		Resources: resourceCount,
+		Broken:    brokenCount,
+		Flaky:     flakyCount,
 	}, nil
 }
 
-// BrokenLink represents a broken link with its href and HTTP status code.
+// BrokenLink represents a broken link or sub-resource with its href, HTTP status code
+// and the issue detected, if any.
 type BrokenLink struct {
-	Href       string `json:"href"`
-	StatusCode int    `json:"status_code"`
+	Href         string       `json:"href"`
+	ResourceType ResourceType `json:"resource_type"`
+	Kind         LinkKind     `json:"kind"`
+	StatusCode   int          `json:"status_code"`
+	CheckMethod  string       `json:"check_method"`
+	Attempts     int          `json:"attempts"`
+	Issue        LinkIssue    `json:"issue,omitempty"`
 }
 
-// GetBrokenLinksByURLID returns all broken links for a given URL ID.
+// GetBrokenLinksByURLID returns all broken links and sub-resources for a given URL ID,
+// including links that responded successfully but have an issue.
 func GetBrokenLinksByURLID(urlID int) ([]BrokenLink, error) {
 	var brokenLinks []BrokenLink
 
 	err := db.DB.
 		Model(&Link{}).
-		Select("href, status_code").
-		Where("url_id = ? AND is_broken = true", urlID).
+		Select("href, resource_type, kind, status_code, check_method, attempts, issue").
+		Where("url_id = ? AND (is_broken = true OR issue <> '')", urlID).
 		Scan(&brokenLinks).Error
 
 	if err != nil {
@@ -0,0 +1,39 @@
+package models
+
+import "urlcrawler/internal/db"
+
+// MixedContentKind classifies an insecure reference found on an HTTPS page.
+type MixedContentKind string
+
+const (
+	MixedContentActive    MixedContentKind = "active"    // Scripts, stylesheets, iframes, fonts loaded over HTTP
+	MixedContentPassive   MixedContentKind = "passive"   // Images, media and favicons loaded over HTTP
+	MixedContentForm      MixedContentKind = "form"      // Form submitting to an HTTP action
+	MixedContentDowngrade MixedContentKind = "downgrade" // Link to the same host over HTTP
+)
+
+// MixedContentIssue represents an insecure reference found on an HTTPS page.
+type MixedContentIssue struct {
+	ID      int              `gorm:"primaryKey;autoIncrement" json:"-"`
+	URLID   int              `gorm:"not null;index" json:"-"`
+	Href    string           `gorm:"not null" json:"href"`
+	Element string           `gorm:"not null" json:"element"` // Resource type, "img", "form" or "link"
+	Kind    MixedContentKind `gorm:"not null" json:"kind"`
+}
+
+// InsertMixedContentIssue inserts a new MixedContentIssue record into the database.
+func InsertMixedContentIssue(m MixedContentIssue) error {
+	return db.DB.Create(&m).Error
+}
+
+// DeleteMixedContentIssuesByURLID deletes all mixed content issues associated with a given URL ID.
+func DeleteMixedContentIssuesByURLID(urlID int) error {
+	return db.DB.Where("url_id = ?", urlID).Delete(&MixedContentIssue{}).Error
+}
+
+// GetMixedContentIssuesByURLID returns all mixed content issues for a given URL ID.
+func GetMixedContentIssuesByURLID(urlID int) ([]MixedContentIssue, error) {
+	var issues []MixedContentIssue
+	err := db.DB.Where("url_id = ?", urlID).Order("id").Find(&issues).Error
+	return issues, err
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+	"urlcrawler/internal/db"
+)
+
+// SecurityResult is the outcome of a single security check, also used as the overall grade.
+type SecurityResult string
+
+const (
+	SecurityPass SecurityResult = "pass"
+	SecurityWarn SecurityResult = "warn"
+	SecurityFail SecurityResult = "fail"
+)
+
+// SecurityCheck is the result of auditing one header or cookie of the crawled page.
+type SecurityCheck struct {
+	Name   string         `json:"name"`
+	Result SecurityResult `json:"result"`
+	Detail string         `json:"detail"`
+}
+
+// SecurityAudit holds the response headers of the crawled page and the
+// header/cookie checks run against them. There is one audit per URL.
+type SecurityAudit struct {
+	ID        int                 `gorm:"primaryKey;autoIncrement" json:"-"`
+	URLID     int                 `gorm:"not null;uniqueIndex" json:"url_id"`
+	Headers   map[string][]string `gorm:"type:text;serializer:json" json:"headers"`
+	Checks    []SecurityCheck     `gorm:"type:text;serializer:json" json:"checks"`
+	Grade     SecurityResult      `gorm:"not null" json:"grade"`
+	CreatedAt time.Time           `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// InsertSecurityAudit inserts a new SecurityAudit record into the database.
+func InsertSecurityAudit(a SecurityAudit) error {
+	return db.DB.Create(&a).Error
+}
+
+// DeleteSecurityAuditByURLID deletes the security audit associated with a given URL ID.
+func DeleteSecurityAuditByURLID(urlID int) error {
+	return db.DB.Where("url_id = ?", urlID).Delete(&SecurityAudit{}).Error
+}
+
+// GetSecurityAuditByURLID retrieves the security audit for a given URL ID.
+func GetSecurityAuditByURLID(urlID int) (*SecurityAudit, error) {
+	var a SecurityAudit
+	err := db.DB.Where("url_id = ?", urlID).First(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
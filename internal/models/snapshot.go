@@ -0,0 +1,68 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"time"
+	"urlcrawler/internal/db"
+)
+
+// PageSnapshot stores the gzip-compressed HTML fetched during a crawl run.
+type PageSnapshot struct {
+	ID          int       `gorm:"primaryKey;autoIncrement"`
+	RunID       int       `gorm:"not null;uniqueIndex"`
+	URLID       int       `gorm:"not null;index"`
+	ContentHash string    `gorm:"not null"` // Hash of the normalized content, see CrawlRun.ContentHash
+	HTML        []byte    `gorm:"not null"` // gzip compressed
+	Size        int       `gorm:"not null"` // Uncompressed size in bytes
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// InsertPageSnapshot compresses and stores the HTML fetched by a crawl run.
+func InsertPageSnapshot(urlID, runID int, contentHash string, html []byte) error {
+	compressed, err := gzipBytes(html)
+	if err != nil {
+		return err
+	}
+
+	return db.DB.Create(&PageSnapshot{
+		RunID:       runID,
+		URLID:       urlID,
+		ContentHash: contentHash,
+		HTML:        compressed,
+		Size:        len(html),
+	}).Error
+}
+
+// GetPageSnapshotHTML returns the decompressed HTML stored for a crawl run of a URL.
+func GetPageSnapshotHTML(urlID, runID int) ([]byte, error) {
+	var snapshot PageSnapshot
+	if err := db.DB.Where("run_id = ? AND url_id = ?", runID, urlID).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return gunzipBytes(snapshot.HTML)
+}
+
+// gzipBytes compresses data for storage in a BLOB column.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipBytes decompresses data stored by gzipBytes.
+func gunzipBytes(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"fmt"
+	"time"
+	"urlcrawler/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// NotificationFrequency defines how often a subscriber is emailed about a URL.
+type NotificationFrequency string
+
+const (
+	NotifyImmediately NotificationFrequency = "immediate" // One email per crawl with news
+	NotifyDaily       NotificationFrequency = "daily"     // A digest of the last day's news
+	NotifyWeekly      NotificationFrequency = "weekly"    // A digest of the last week's news
+)
+
+// EmailSubscription subscribes a user to emails about new broken links and failed crawls of a URL.
+type EmailSubscription struct {
+	ID           int                   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       int                   `gorm:"not null;uniqueIndex:idx_email_subscription" json:"user_id"`
+	URLID        int                   `gorm:"not null;uniqueIndex:idx_email_subscription" json:"url_id"`
+	Frequency    NotificationFrequency `gorm:"not null" json:"frequency"`
+	NextDigestAt *time.Time            `json:"next_digest_at"` // When the next digest is due; nil for immediate emails
+	CreatedAt    time.Time             `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// EmailSubscriber is a subscription joined with the email address of its user.
+type EmailSubscriber struct {
+	SubscriptionID int
+	UserID         int
+	URLID          int
+	Email          string
+	Frequency      NotificationFrequency
+}
+
+// SaveEmailSubscription subscribes a user to a URL or changes the frequency of an
+// existing subscription. Notifications waiting for a digest are kept for the next
+// digest, or dropped when switching to immediate emails.
+func SaveEmailSubscription(s *EmailSubscription) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []EmailSubscription
+		if err := tx.Where("user_id = ? AND url_id = ?", s.UserID, s.URLID).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return tx.Create(s).Error
+		}
+		s.ID = existing[0].ID
+		s.CreatedAt = existing[0].CreatedAt
+		if s.Frequency == NotifyImmediately {
+			if err := tx.Where("subscription_id = ?", s.ID).Delete(&DigestItem{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(s).Error
+	})
+}
+
+// GetEmailSubscriptionsByUserID returns the subscriptions of a user.
+func GetEmailSubscriptionsByUserID(userID int) ([]EmailSubscription, error) {
+	var subs []EmailSubscription
+	err := db.DB.Where("user_id = ?", userID).Order("url_id").Find(&subs).Error
+	return subs, err
+}
+
+// DeleteEmailSubscription unsubscribes a user from a URL, discarding notifications waiting for a digest.
+func DeleteEmailSubscription(userID, urlID int) error {
+	result := db.DB.Where("user_id = ? AND url_id = ?", userID, urlID).Delete(&EmailSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Not subscribed to URL with ID %d", urlID)
+	}
+	return nil
+}
+
+// GetEmailSubscribers returns the subscribers of a URL.
+func GetEmailSubscribers(urlID int) ([]EmailSubscriber, error) {
+	var subscribers []EmailSubscriber
+	err := db.DB.
+		Table("email_subscriptions").
+		Select("email_subscriptions.id AS subscription_id, email_subscriptions.user_id, email_subscriptions.url_id, users.email, email_subscriptions.frequency").
+		Joins("JOIN users ON users.id = email_subscriptions.user_id").
+		Where("email_subscriptions.url_id = ?", urlID).
+		Scan(&subscribers).Error
+	return subscribers, err
+}
+
+// GetDueDigestSubscribers returns the digest subscriptions whose next digest is not after now.
+func GetDueDigestSubscribers(now time.Time) ([]EmailSubscriber, error) {
+	var subscribers []EmailSubscriber
+	err := db.DB.
+		Table("email_subscriptions").
+		Select("email_subscriptions.id AS subscription_id, email_subscriptions.user_id, email_subscriptions.url_id, users.email, email_subscriptions.frequency").
+		Joins("JOIN users ON users.id = email_subscriptions.user_id").
+		Where("email_subscriptions.frequency <> ? AND email_subscriptions.next_digest_at <= ?", NotifyImmediately, now).
+		Order("email_subscriptions.user_id, email_subscriptions.url_id").
+		Scan(&subscribers).Error
+	return subscribers, err
+}
+
+// UpdateNextDigestAt sets when the next digest of a subscription is due.
+func UpdateNextDigestAt(subscriptionID int, next time.Time) error {
+	return db.DB.Model(&EmailSubscription{}).Where("id = ?", subscriptionID).Update("next_digest_at", next).Error
+}
+
+// DigestItem is a notification waiting to be sent in the next digest of a subscription.
+type DigestItem struct {
+	ID             int       `gorm:"primaryKey;autoIncrement"`
+	SubscriptionID int       `gorm:"not null;index"`
+	Subject        string    `gorm:"not null"`
+	Body           string    `gorm:"type:text;not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// InsertDigestItem queues a notification for the next digest of a subscription.
+func InsertDigestItem(item *DigestItem) error {
+	return db.DB.Create(item).Error
+}
+
+// GetDigestItems returns the queued notifications of the given subscriptions, oldest first.
+func GetDigestItems(subscriptionIDs []int) ([]DigestItem, error) {
+	var items []DigestItem
+	err := db.DB.Where("subscription_id IN ?", subscriptionIDs).Order("id").Find(&items).Error
+	return items, err
+}
+
+// DeleteDigestItems removes sent notifications.
+func DeleteDigestItems(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.DB.Where("id IN ?", ids).Delete(&DigestItem{}).Error
+}
+
+// BrokenLinkSnapshot holds the broken links of a URL as of the last notification,
+// so subscribers are only told about links that broke since.
+type BrokenLinkSnapshot struct {
+	URLID     int       `gorm:"primaryKey;autoIncrement:false"`
+	Hrefs     []string  `gorm:"type:mediumtext;serializer:json"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// GetBrokenLinkSnapshot returns the broken links of a URL as of the last notification,
+// or nil if there is no snapshot yet.
+func GetBrokenLinkSnapshot(urlID int) (*BrokenLinkSnapshot, error) {
+	var snapshots []BrokenLinkSnapshot
+	if err := db.DB.Where("url_id = ?", urlID).Limit(1).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return &snapshots[0], nil
+}
+
+// SaveBrokenLinkSnapshot stores the current broken links of a URL.
+func SaveBrokenLinkSnapshot(urlID int, hrefs []string) error {
+	return db.DB.Save(&BrokenLinkSnapshot{URLID: urlID, Hrefs: hrefs}).Error
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 	"urlcrawler/internal/db"
+	"urlcrawler/internal/events"
 
 	"gorm.io/gorm"
 )
@@ -17,6 +18,7 @@ const (
 	URLStatusDone       URLStatus = "done"
 	URLStatusError      URLStatus = "error"
 	URLStatusStopped    URLStatus = "stopped"
+	URLStatusPaused     URLStatus = "paused"
 )
 
 // URL represents a crawled URL record with metadata and status.
@@ -27,6 +29,13 @@ type URL struct {
 	Title        string
 	HTMLVersion  string
 	HasLoginForm bool
+	MixedContentCount int // Number of insecure references found on an HTTPS page
+	VolatileSelectors string // Comma separated CSS selectors ignored when hashing page content
+	ETag         string `gorm:"column:etag"` // Validators of the last analysed response, sent on the next crawl
+	LastModified string
+	Schedule     string     // Cron expression or interval for recurring crawls; empty if not scheduled
+	NextRunAt    *time.Time // When the scheduler will next start a crawl
+	LastRunAt    *time.Time // When the scheduler last started a crawl
 	Status       URLStatus
 	ErrorMessage string
 	CreatedAt    time.Time `gorm:"autoCreateTime"`
@@ -48,22 +57,85 @@ func GetURLByID(id int) (*URL, error) {
 	return &u, nil
 }
 
-// UpdateURLStatus updates only the status field of a URL record.
+// UpdateURLStatus updates only the status field of a URL record and announces the change.
 func UpdateURLStatus(urlID int, status URLStatus) error {
-	return db.DB.Model(&URL{}).
+	err := db.DB.Model(&URL{}).
 		Where("id = ?", urlID).
 		Update("status", status).
 		Error
+	if err == nil {
+		events.Publish(events.Event{Type: events.TypeStatus, URLID: urlID, Status: string(status)})
+	}
+	return err
 }
 
-// UpdateURLStatusWithError updates the status and error message fields of a URL record.
+// UpdateURLStatusWithError updates the status and error message fields of a URL record and announces the change.
 func UpdateURLStatusWithError(urlID int, status URLStatus, errMsg string) error {
-	return db.DB.Model(&URL{}).
+	err := db.DB.Model(&URL{}).
 		Where("id = ?", urlID).
 		Updates(map[string]interface{}{
 			"status":        status,
 			"error_message": errMsg,
 		}).Error
+	if err == nil {
+		events.Publish(events.Event{Type: events.TypeStatus, URLID: urlID, Status: string(status), Error: errMsg})
+	}
+	return err
+}
+
+// ClearURLValidators removes the stored ETag and Last-Modified of a URL so the next
+// crawl downloads the page unconditionally.
+func ClearURLValidators(urlID int) error {
+	return db.DB.Model(&URL{}).
+		Where("id = ?", urlID).
+		Updates(map[string]interface{}{
+			"etag":          "",
+			"last_modified": "",
+		}).Error
+}
+
+// GetDueScheduledURLs returns the scheduled URLs whose next run time is not after now.
+func GetDueScheduledURLs(now time.Time) ([]URL, error) {
+	var urls []URL
+	err := db.DB.
+		Where("schedule <> '' AND next_run_at IS NOT NULL AND next_run_at <= ?", now).
+		Order("next_run_at").
+		Find(&urls).Error
+	return urls, err
+}
+
+// GetScheduledURLs returns all URLs with a schedule, the soonest due first.
+func GetScheduledURLs() ([]URL, error) {
+	var urls []URL
+	err := db.DB.Where("schedule <> ''").Order("next_run_at").Find(&urls).Error
+	return urls, err
+}
+
+// UpdateURLScheduleTimes sets the next run time of a URL and, if lastRun is not nil, its last run time.
+func UpdateURLScheduleTimes(urlID int, nextRun, lastRun *time.Time) error {
+	updates := map[string]interface{}{"next_run_at": nextRun}
+	if lastRun != nil {
+		updates["last_run_at"] = lastRun
+	}
+	return db.DB.Model(&URL{}).Where("id = ?", urlID).Updates(updates).Error
+}
+
+// UpdateURLSchedule sets the crawl schedule of a URL along with its next run time.
+func UpdateURLSchedule(urlID int, schedule string, nextRun *time.Time) error {
+	return db.DB.Model(&URL{}).
+		Where("id = ?", urlID).
+		Updates(map[string]interface{}{
+			"schedule":    schedule,
+			"next_run_at": nextRun,
+		}).Error
+}
+
+// UpdateURLVolatileSelectors sets the CSS selectors ignored when hashing the content of a URL.
+func UpdateURLVolatileSelectors(urlID int, selectors string) error {
+	return db.DB.Model(&URL{}).
+		Where("id = ?", urlID).
+		Update("volatile_selectors", selectors).
+		Error
 }
 
 // UpdateURL saves the full URL struct, updating all fields.
@@ -71,6 +143,23 @@ func UpdateURL(u *URL) error {
 	return db.DB.Save(u).Error
 }
 
+// UpdateURLCrawlResult saves the fields a crawl sets on a URL. The crawl holds a copy
+// loaded when it started, so the other fields, such as the schedule and volatile
+// selectors, may have been changed since and are left as stored.
+func UpdateURLCrawlResult(u *URL) error {
+	return db.DB.Model(&URL{}).
+		Where("id = ?", u.ID).
+		Updates(map[string]interface{}{
+			"title":               u.Title,
+			"html_version":        u.HTMLVersion,
+			"etag":                u.ETag,
+			"last_modified":       u.LastModified,
+			"mixed_content_count": u.MixedContentCount,
+			"status":              u.Status,
+			"updated_at":          u.UpdatedAt,
+		}).Error
+}
+
 // GetAllURLs returns all URL records ordered by last update descending.
 func GetAllURLs() ([]URL, error) {
 	var urls []URL
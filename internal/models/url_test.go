@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	"testing"
+
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+func TestUpdateURLCrawlResultKeepsConcurrentChanges(t *testing.T) {
+	dbtest.Open(t, &models.URL{})
+
+	u := &models.URL{UserID: 1, URL: "https://example.com", Status: models.URLStatusProcessing}
+	if err := models.InsertURL(u); err != nil {
+		t.Fatalf("failed to insert URL: %v", err)
+	}
+
+	// An admin changes the volatile selectors while the crawl is running
+	crawled, _ := models.GetURLByID(u.ID)
+	if err := models.UpdateURLVolatileSelectors(u.ID, ".ad"); err != nil {
+		t.Fatalf("failed to update volatile selectors: %v", err)
+	}
+
+	crawled.Title = "Example"
+	crawled.Status = models.URLStatusDone
+	if err := models.UpdateURLCrawlResult(crawled); err != nil {
+		t.Fatalf("failed to save crawl result: %v", err)
+	}
+
+	stored, _ := models.GetURLByID(u.ID)
+	if stored.VolatileSelectors != ".ad" {
+		t.Errorf("volatile selectors = %q, want %q", stored.VolatileSelectors, ".ad")
+	}
+	if stored.Title != "Example" || stored.Status != models.URLStatusDone {
+		t.Errorf("crawl result not saved: title %q, status %q", stored.Title, stored.Status)
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"time"
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/db"
+)
+
+// WebhookEvent names an event a webhook can subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookCrawlDone        WebhookEvent = "crawl.done"
+	WebhookCrawlError       WebhookEvent = "crawl.error"
+	WebhookBrokenLinksFound WebhookEvent = "broken_links.found" // A completed crawl found at least one broken link
+	WebhookAlertFiring      WebhookEvent = "alert.firing"       // An alert rule started firing
+	WebhookAlertResolved    WebhookEvent = "alert.resolved"     // A firing alert rule resolved
+)
+
+// WebhookEvents lists every event a webhook can subscribe to.
+var WebhookEvents = []WebhookEvent{WebhookCrawlDone, WebhookCrawlError, WebhookBrokenLinksFound, WebhookAlertFiring, WebhookAlertResolved}
+
+// Webhook is an endpoint notified of crawl events. Payloads are signed with its
+// secret, which is encrypted at rest like crawl credentials.
+type Webhook struct {
+	ID              int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	URL             string         `gorm:"not null" json:"url"`
+	Events          []WebhookEvent `gorm:"type:text;serializer:json" json:"events"`
+	EncryptedSecret string         `gorm:"not null" json:"-"`
+	Active          bool           `gorm:"not null;default:true" json:"active"`
+	CreatedBy       int            `gorm:"not null" json:"created_by"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Subscribes reports whether the webhook wants the given event.
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Secret decrypts the signing secret of the webhook.
+func (w *Webhook) Secret() ([]byte, error) {
+	return auth.DecryptSecret(w.EncryptedSecret)
+}
+
+// InsertWebhook encrypts the signing secret and stores a new webhook.
+func InsertWebhook(w *Webhook, secret string) error {
+	encrypted, err := auth.EncryptSecret([]byte(secret))
+	if err != nil {
+		return err
+	}
+	w.EncryptedSecret = encrypted
+	return db.DB.Create(w).Error
+}
+
+// GetWebhooks returns all registered webhooks.
+func GetWebhooks() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := db.DB.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetWebhookByID returns a webhook by its ID.
+func GetWebhookByID(id int) (*Webhook, error) {
+	var w Webhook
+	if err := db.DB.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetActiveWebhooks returns the webhooks that are currently enabled.
+func GetActiveWebhooks() ([]Webhook, error) {
+	var webhooks []Webhook
+	err := db.DB.Where("active = true").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// DeleteWebhook deletes a webhook; its delivery log is removed by DB constraints.
+func DeleteWebhook(id int) error {
+	result := db.DB.Delete(&Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Webhook with ID %d not found", id)
+	}
+	return nil
+}
+
+// WebhookDelivery records one event sent to a webhook and the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID            int          `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID     int          `gorm:"not null;index" json:"webhook_id"`
+	Event         WebhookEvent `gorm:"not null" json:"event"`
+	URLID         int          `gorm:"not null" json:"url_id"`
+	Payload       string       `gorm:"type:mediumtext;not null" json:"payload"`
+	Attempts      int          `gorm:"not null" json:"attempts"`
+	StatusCode    int          `gorm:"not null" json:"status_code"` // Of the last attempt; 0 if no response was received
+	Error         string       `json:"error,omitempty"`
+	Success       bool         `gorm:"not null" json:"success"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	NextAttemptAt *time.Time   `gorm:"index" json:"next_attempt_at"` // When the next attempt is due, or claimed until; nil once completed
+	CompletedAt   *time.Time   `json:"completed_at"`                 // Set once delivered or out of retries
+}
+
+// InsertWebhookDelivery stores a new pending delivery.
+func InsertWebhookDelivery(d *WebhookDelivery) error {
+	return db.DB.Create(d).Error
+}
+
+// GetDueWebhookDeliveries returns up to limit pending deliveries whose next attempt is not after now.
+func GetDueWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.DB.
+		Where("completed_at IS NULL AND next_attempt_at <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimWebhookDelivery postpones the next attempt of a pending delivery to until, so
+// that no other process attempts it meanwhile. It returns false if the delivery was
+// claimed or completed since it was loaded.
+func ClaimWebhookDelivery(d *WebhookDelivery, until time.Time) (bool, error) {
+	result := db.DB.Model(&WebhookDelivery{}).
+		Where("id = ? AND completed_at IS NULL AND next_attempt_at = ?", d.ID, d.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	d.NextAttemptAt = &until
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery attempt.
+func UpdateWebhookDelivery(d *WebhookDelivery) error {
+	return db.DB.Save(d).Error
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a webhook, most recent first.
+func GetWebhookDeliveries(webhookID, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.DB.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
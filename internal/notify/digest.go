@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/models"
+)
+
+// NextDigest returns when a digest of the given frequency is due after t: daily at
+// the configured hour, weekly on Mondays at that hour. It is nil for immediate emails.
+func NextDigest(freq models.NotificationFrequency, t time.Time) *time.Time {
+	var days int
+	switch freq {
+	case models.NotifyDaily:
+		days = 1
+	case models.NotifyWeekly:
+		days = 7
+	default:
+		return nil
+	}
+
+	hour := min(max(config.Cfg.DigestHour, 0), 23)
+	next := time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+	if freq == models.NotifyWeekly {
+		next = next.AddDate(0, 0, (int(time.Monday)-int(next.Weekday())+7)%7)
+	}
+	if !next.After(t) {
+		next = next.AddDate(0, 0, days)
+	}
+	return &next
+}
+
+// RunDigests sends the digests of subscribers as they become due until ctx is cancelled.
+func RunDigests(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sendDueDigests(now)
+		}
+	}
+}
+
+// digestKey groups the due subscriptions of a user into one email per frequency.
+type digestKey struct {
+	email     string
+	frequency models.NotificationFrequency
+}
+
+// sendDueDigests emails each user the notifications queued for their due digest
+// subscriptions. The next digest is always scheduled; notifications that could
+// not be sent are kept for it.
+func sendDueDigests(now time.Time) {
+	subscribers, err := models.GetDueDigestSubscribers(now)
+	if err != nil {
+		log.Printf("⚠️ Failed to load due digests: %v", err)
+		return
+	}
+
+	groups := map[digestKey][]int{}
+	var keys []digestKey
+	for _, s := range subscribers {
+		key := digestKey{email: s.Email, frequency: s.Frequency}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s.SubscriptionID)
+
+		if next := NextDigest(s.Frequency, now); next != nil {
+			if err := models.UpdateNextDigestAt(s.SubscriptionID, *next); err != nil {
+				log.Printf("⚠️ Failed to schedule next digest of subscription ID %d: %v", s.SubscriptionID, err)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		items, err := models.GetDigestItems(groups[key])
+		if err != nil {
+			log.Printf("⚠️ Failed to load digest of %s: %v", key.email, err)
+			continue
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		if !send(formatDigest(key.email, key.frequency, items)) {
+			continue
+		}
+
+		ids := make([]int, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+		if err := models.DeleteDigestItems(ids); err != nil {
+			log.Printf("⚠️ Failed to clear sent digest of %s: %v", key.email, err)
+		}
+	}
+}
+
+// formatDigest combines queued notifications into a single email.
+func formatDigest(to string, freq models.NotificationFrequency, items []models.DigestItem) Message {
+	period := "Daily"
+	if freq == models.NotifyWeekly {
+		period = "Weekly"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s digest of your URL subscriptions: %s.\n", period, plural(len(items), "notification"))
+	for _, item := range items {
+		fmt.Fprintf(&b, "\n== %s ==\n\n%s", item.Subject, item.Body)
+	}
+
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("%s%s digest: %s", subjectPrefix, period, plural(len(items), "notification")),
+		Body:    b.String(),
+	}
+}
@@ -0,0 +1,73 @@
+// Package notify emails subscribers about new broken links and failed crawls.
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. Notifications are disabled until one is set.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var mailer Mailer
+
+// SetMailer sets the mailer used for all notifications; nil disables them.
+func SetMailer(m Mailer) {
+	mailer = m
+}
+
+// Enabled reports whether a mailer is set.
+func Enabled() bool {
+	return mailer != nil
+}
+
+// SMTPMailer sends emails through an SMTP server, using STARTTLS when the server offers it.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string // Authentication is skipped if empty
+	Password string
+	From     string
+}
+
+// Send delivers a message through the SMTP server.
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	return smtp.SendMail(addr, auth, m.From, []string{msg.To}, formatMessage(m.From, msg, time.Now()))
+}
+
+// headerValue strips line breaks, which would otherwise inject headers.
+var headerValue = strings.NewReplacer("\r", "", "\n", " ")
+
+// formatMessage renders a message as a MIME email with CRLF line endings.
+func formatMessage(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue.Replace(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue.Replace(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/models"
+)
+
+const subjectPrefix = "[URL Crawler] "
+
+// notification is the news of a finished crawl or alert, sent to each subscriber either
+// right away or as part of a digest.
+type notification struct {
+	Subject string
+	Body    string
+}
+
+// HandleCrawlFinished notifies the subscribers of a URL when its crawl failed or found
+// broken links that were not broken at the previous notification. It is registered
+// with crawler.OnCrawlFinished.
+func HandleCrawlFinished(f crawler.FinishedCrawl) {
+	n, err := buildNotification(f)
+	if err != nil {
+		log.Printf("⚠️ Failed to build notification for URL ID %d: %v", f.URLID, err)
+		return
+	}
+	if n != nil {
+		notifySubscribers(f.URLID, n)
+	}
+}
+
+// HandleAlert notifies the subscribers of a URL that one of its alert rules changed state.
+func HandleAlert(rule *models.AlertRule) {
+	urlObj, err := models.GetURLByID(rule.URLID)
+	if err != nil {
+		log.Printf("⚠️ Failed to build notification for URL ID %d: %v", rule.URLID, err)
+		return
+	}
+
+	state := "Alert firing"
+	if rule.State == models.AlertResolved {
+		state = "Alert resolved"
+	}
+	notifySubscribers(rule.URLID, &notification{
+		Subject: fmt.Sprintf("%s for %s: %s", state, urlObj.URL, rule.Kind.Describe(rule.Threshold)),
+		Body:    fmt.Sprintf("%s for %s on %s.\n\n  %s\n", state, urlObj.URL, time.Now().Format(time.RFC1123), rule.Message),
+	})
+}
+
+// notifySubscribers emails a notification to the immediate subscribers of a URL and
+// queues it for the next digest of the others.
+func notifySubscribers(urlID int, n *notification) {
+	subscribers, err := models.GetEmailSubscribers(urlID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load subscribers of URL ID %d: %v", urlID, err)
+		return
+	}
+
+	for _, s := range subscribers {
+		if s.Frequency != models.NotifyImmediately {
+			item := &models.DigestItem{SubscriptionID: s.SubscriptionID, Subject: n.Subject, Body: n.Body}
+			if err := models.InsertDigestItem(item); err != nil {
+				log.Printf("⚠️ Failed to queue digest notification for subscription ID %d: %v", s.SubscriptionID, err)
+			}
+			continue
+		}
+		// Sent off the worker goroutine, which must not wait on the SMTP server
+		go send(Message{To: s.Email, Subject: subjectPrefix + n.Subject, Body: n.Body})
+	}
+}
+
+// buildNotification returns the news of a finished crawl, or nil if there is none.
+// For a completed crawl the URL's broken link snapshot is brought up to date.
+func buildNotification(f crawler.FinishedCrawl) (*notification, error) {
+	urlObj, err := models.GetURLByID(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Format(time.RFC1123)
+
+	if f.Err != nil {
+		errMsg := urlObj.ErrorMessage
+		if errMsg == "" {
+			errMsg = f.Err.Error()
+		}
+		return &notification{
+			Subject: "Crawl failed for " + urlObj.URL,
+			Body:    fmt.Sprintf("The crawl of %s failed on %s:\n\n  %s\n", urlObj.URL, now, errMsg),
+		}, nil
+	}
+
+	brokenLinks, err := models.GetBrokenLinksByURLID(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := models.GetBrokenLinkSnapshot(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	if snapshot != nil {
+		for _, href := range snapshot.Hrefs {
+			known[href] = true
+		}
+	}
+	hrefs := make([]string, 0, len(brokenLinks))
+	var newLinks []models.BrokenLink
+	for _, link := range brokenLinks {
+		hrefs = append(hrefs, link.Href)
+		if !known[link.Href] {
+			newLinks = append(newLinks, link)
+		}
+	}
+	if err := models.SaveBrokenLinkSnapshot(f.URLID, hrefs); err != nil {
+		return nil, err
+	}
+	if len(newLinks) == 0 {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "The crawl of %s", urlObj.URL)
+	if urlObj.Title != "" {
+		fmt.Fprintf(&b, " (%q)", urlObj.Title)
+	}
+	fmt.Fprintf(&b, " finished on %s and found %s:\n\n", now, plural(len(newLinks), "new broken link"))
+	for _, link := range newLinks {
+		fmt.Fprintf(&b, "  - %s (%s)\n", link.Href, describeBrokenLink(link))
+	}
+	fmt.Fprintf(&b, "\nThe page now has %s in total.\n", plural(len(brokenLinks), "broken link"))
+
+	return &notification{
+		Subject: fmt.Sprintf("%s on %s", plural(len(newLinks), "new broken link"), urlObj.URL),
+		Body:    b.String(),
+	}, nil
+}
+
+// describeBrokenLink returns why a link is reported: its issue, or else its status code.
+func describeBrokenLink(link models.BrokenLink) string {
+	switch {
+	case link.Issue != "":
+		return strings.ReplaceAll(string(link.Issue), "_", " ")
+	case link.StatusCode == 0:
+		return "no response"
+	default:
+		return fmt.Sprintf("status %d", link.StatusCode)
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// send delivers a message with the configured mailer, logging failures.
+func send(msg Message) bool {
+	if mailer == nil {
+		return false
+	}
+	if err := mailer.Send(msg); err != nil {
+		log.Printf("⚠️ Failed to email %s: %v", msg.To, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+// fakeMailer keeps sent messages in memory.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (m *fakeMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// waitForMessages returns the messages sent so far once there are n, failing after a timeout.
+// Immediate notifications are sent from their own goroutine.
+func (m *fakeMailer) waitForMessages(t *testing.T, n int) []Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		m.mu.Lock()
+		sent := append([]Message{}, m.sent...)
+		m.mu.Unlock()
+		if len(sent) >= n {
+			return sent
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d messages, want %d: %+v", len(sent), n, sent)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// setup opens a test database with one URL and installs a fake mailer.
+func setup(t *testing.T) (*fakeMailer, *models.URL) {
+	dbtest.Open(t, &models.User{}, &models.URL{}, &models.Link{},
+		&models.EmailSubscription{}, &models.DigestItem{}, &models.BrokenLinkSnapshot{})
+
+	m := &fakeMailer{}
+	SetMailer(m)
+	t.Cleanup(func() { SetMailer(nil) })
+
+	urlObj := &models.URL{UserID: 1, URL: "https://example.com/", Status: models.URLStatusDone}
+	if err := models.InsertURL(urlObj); err != nil {
+		t.Fatalf("failed to insert URL: %v", err)
+	}
+	return m, urlObj
+}
+
+// subscribe creates a user subscribed to a URL with the given frequency.
+func subscribe(t *testing.T, email string, urlID int, freq models.NotificationFrequency) *models.EmailSubscription {
+	t.Helper()
+	user, err := models.CreateUser("Test", "User", email, "hash", models.UserRoleUser)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	sub := &models.EmailSubscription{UserID: user.ID, URLID: urlID, Frequency: freq, NextDigestAt: NextDigest(freq, time.Now())}
+	if err := models.SaveEmailSubscription(sub); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	return sub
+}
+
+// setBrokenLinks replaces the links of a URL with the given broken hrefs and one working link.
+func setBrokenLinks(t *testing.T, urlID int, hrefs ...string) {
+	t.Helper()
+	if err := models.DeleteLinksByURLID(urlID); err != nil {
+		t.Fatal(err)
+	}
+	models.InsertLink(models.Link{URLID: urlID, Href: "https://example.com/ok", ResourceType: models.ResourceLink, Kind: models.LinkKindHTTP, StatusCode: 200})
+	for _, href := range hrefs {
+		models.InsertLink(models.Link{URLID: urlID, Href: href, ResourceType: models.ResourceLink, Kind: models.LinkKindHTTP, StatusCode: 404, IsBroken: true})
+	}
+}
+
+func TestOnlyNewBrokenLinksAreReported(t *testing.T) {
+	m, urlObj := setup(t)
+	subscribe(t, "alice@example.com", urlObj.ID, models.NotifyImmediately)
+
+	setBrokenLinks(t, urlObj.ID, "https://example.com/a")
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID})
+	sent := m.waitForMessages(t, 1)
+	if msg := sent[0]; msg.To != "alice@example.com" || !strings.Contains(msg.Subject, "1 new broken link") || !strings.Contains(msg.Body, "https://example.com/a (status 404)") {
+		t.Errorf("first notification = %+v", msg)
+	}
+
+	// The same broken link is not reported twice
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID})
+
+	// A newly broken link is reported alone, with the total
+	setBrokenLinks(t, urlObj.ID, "https://example.com/a", "https://example.com/b")
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID})
+	sent = m.waitForMessages(t, 2)
+	if len(sent) != 2 {
+		t.Fatalf("got %d messages, want 2", len(sent))
+	}
+	if body := sent[1].Body; !strings.Contains(body, "https://example.com/b") || strings.Contains(body, "https://example.com/a ") || !strings.Contains(body, "2 broken links in total") {
+		t.Errorf("second notification body:\n%s", body)
+	}
+
+	// A link that was fixed and broke again is new again
+	setBrokenLinks(t, urlObj.ID, "https://example.com/b")
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID})
+	setBrokenLinks(t, urlObj.ID, "https://example.com/a", "https://example.com/b")
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID})
+	sent = m.waitForMessages(t, 3)
+	if len(sent) != 3 || !strings.Contains(sent[2].Body, "https://example.com/a") {
+		t.Errorf("messages after the link broke again: %+v", sent)
+	}
+}
+
+func TestImmediateAndDigestSubscribers(t *testing.T) {
+	m, urlObj := setup(t)
+	subscribe(t, "alice@example.com", urlObj.ID, models.NotifyImmediately)
+	daily := subscribe(t, "bob@example.com", urlObj.ID, models.NotifyDaily)
+	weekly := subscribe(t, "carol@example.com", urlObj.ID, models.NotifyWeekly)
+
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID, Err: errors.New("connection refused")})
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID, Err: errors.New("timeout")})
+
+	// Immediate subscribers get one email per crawl; the others wait for their digest
+	sent := m.waitForMessages(t, 2)
+	for _, msg := range sent {
+		if msg.To != "alice@example.com" || !strings.HasPrefix(msg.Subject, subjectPrefix+"Crawl failed for") {
+			t.Errorf("immediate notification = %+v", msg)
+		}
+	}
+	items, err := models.GetDigestItems([]int{daily.ID, weekly.ID})
+	if err != nil || len(items) != 4 {
+		t.Fatalf("queued digest items = %d (%v), want 4", len(items), err)
+	}
+
+	// Only the daily digest is due
+	now := time.Now()
+	models.UpdateNextDigestAt(daily.ID, now.Add(-time.Minute))
+	models.UpdateNextDigestAt(weekly.ID, now.Add(time.Hour))
+	sendDueDigests(now)
+	sent = m.waitForMessages(t, 3)
+	if len(sent) != 3 {
+		t.Fatalf("got %d messages, want 3", len(sent))
+	}
+	digest := sent[2]
+	if digest.To != "bob@example.com" || digest.Subject != subjectPrefix+"Daily digest: 2 notifications" ||
+		!strings.Contains(digest.Body, "connection refused") || !strings.Contains(digest.Body, "timeout") {
+		t.Errorf("daily digest = %+v", digest)
+	}
+	if items, _ := models.GetDigestItems([]int{daily.ID}); len(items) != 0 {
+		t.Errorf("%d items left after the daily digest was sent", len(items))
+	}
+	if items, _ := models.GetDigestItems([]int{weekly.ID}); len(items) != 2 {
+		t.Errorf("%d weekly items left, want 2", len(items))
+	}
+
+	// The next digests are scheduled and nothing is sent twice
+	subs, _ := models.GetEmailSubscriptionsByUserID(daily.UserID)
+	if len(subs) != 1 || subs[0].NextDigestAt == nil || !subs[0].NextDigestAt.After(now) {
+		t.Errorf("next daily digest not scheduled: %+v", subs)
+	}
+	sendDueDigests(now.Add(2 * time.Hour))
+	sent = m.waitForMessages(t, 4)
+	if len(sent) != 4 || sent[3].To != "carol@example.com" || sent[3].Subject != subjectPrefix+"Weekly digest: 2 notifications" {
+		t.Errorf("messages after the weekly digest: %+v", sent)
+	}
+}
+
+func TestSwitchingToImmediateDropsQueuedItems(t *testing.T) {
+	_, urlObj := setup(t)
+	sub := subscribe(t, "bob@example.com", urlObj.ID, models.NotifyDaily)
+	HandleCrawlFinished(crawler.FinishedCrawl{URLID: urlObj.ID, Err: errors.New("timeout")})
+
+	// Changing between digest frequencies keeps the queued items
+	weekly := &models.EmailSubscription{UserID: sub.UserID, URLID: urlObj.ID, Frequency: models.NotifyWeekly}
+	if err := models.SaveEmailSubscription(weekly); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := models.GetDigestItems([]int{sub.ID}); len(items) != 1 {
+		t.Fatalf("%d items queued after switching to weekly, want 1", len(items))
+	}
+
+	immediate := &models.EmailSubscription{UserID: sub.UserID, URLID: urlObj.ID, Frequency: models.NotifyImmediately}
+	if err := models.SaveEmailSubscription(immediate); err != nil {
+		t.Fatal(err)
+	}
+	if immediate.ID != sub.ID {
+		t.Errorf("subscription ID changed from %d to %d", sub.ID, immediate.ID)
+	}
+	if items, _ := models.GetDigestItems([]int{sub.ID}); len(items) != 0 {
+		t.Errorf("%d items still queued after switching to immediate", len(items))
+	}
+}
@@ -0,0 +1,348 @@
+// Package webhooks notifies registered endpoints of finished crawls.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/crawler"
+	"urlcrawler/internal/models"
+)
+
+// Payload is the JSON body sent to webhooks.
+type Payload struct {
+	Event       models.WebhookEvent `json:"event"`
+	Timestamp   time.Time           `json:"timestamp"`
+	URL         PayloadURL          `json:"url"`
+	Run         *models.CrawlRun    `json:"run"`                    // Latest crawl run of the URL
+	LinkCount   *models.LinkCount   `json:"link_count,omitempty"`   // Only for completed crawls
+	BrokenLinks []models.BrokenLink `json:"broken_links,omitempty"` // Only for broken_links.found
+	Alert       *models.AlertRule   `json:"alert,omitempty"`        // Only for alert.firing and alert.resolved
+}
+
+// PayloadURL describes the crawled URL in a payload.
+type PayloadURL struct {
+	ID           int              `json:"id"`
+	URL          string           `json:"url"`
+	Title        string           `json:"title"`
+	Status       models.URLStatus `json:"status"`
+	ErrorMessage string           `json:"error_message,omitempty"`
+}
+
+// noRedirect makes receivers answer directly; following a redirect would resend the signed payload elsewhere.
+func noRedirect(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// GenerateSecret returns a random signing secret for a new webhook.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Webhook-Signature value of a body: "sha256=" followed by
+// the hex encoded HMAC-SHA256 of the body keyed with the webhook's secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HandleCrawlFinished queues the events of a finished crawl for every active webhook
+// subscribed to them. It is registered with crawler.OnCrawlFinished.
+func HandleCrawlFinished(f crawler.FinishedCrawl) {
+	webhooks := activeWebhooks(f.URLID)
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payloads, err := buildPayloads(f)
+	if err != nil {
+		log.Printf("⚠️ Failed to build webhook payload for URL ID %d: %v", f.URLID, err)
+		return
+	}
+	for _, p := range payloads {
+		dispatch(webhooks, p)
+	}
+}
+
+// HandleAlert queues an alert.firing or alert.resolved event for a rule that changed state.
+func HandleAlert(rule *models.AlertRule) {
+	webhooks := activeWebhooks(rule.URLID)
+	if len(webhooks) == 0 {
+		return
+	}
+
+	event := models.WebhookAlertResolved
+	if rule.State == models.AlertFiring {
+		event = models.WebhookAlertFiring
+	}
+
+	p, err := basePayload(rule.URLID)
+	if err != nil {
+		log.Printf("⚠️ Failed to build webhook payload for URL ID %d: %v", rule.URLID, err)
+		return
+	}
+	p.Event = event
+	p.Alert = rule
+	dispatch(webhooks, p)
+}
+
+// activeWebhooks returns the enabled webhooks, logging failures as concerning the given URL.
+func activeWebhooks(urlID int) []models.Webhook {
+	webhooks, err := models.GetActiveWebhooks()
+	if err != nil {
+		log.Printf("⚠️ Failed to load webhooks for URL ID %d: %v", urlID, err)
+	}
+	return webhooks
+}
+
+// dispatch sends a payload to each of the webhooks subscribed to its event,
+// logging every delivery before it is attempted. The first attempt is made right
+// away by this process, which claims the delivery as it is logged.
+func dispatch(webhooks []models.Webhook, p Payload) {
+	var body []byte
+	var err error
+	for i := range webhooks {
+		w := &webhooks[i]
+		if !w.Subscribes(p.Event) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(p); err != nil {
+				log.Printf("⚠️ Failed to encode webhook payload for URL ID %d: %v", p.URL.ID, err)
+				return
+			}
+		}
+		claimedUntil := time.Now().Add(attemptLease())
+		delivery := &models.WebhookDelivery{
+			WebhookID:     w.ID,
+			Event:         p.Event,
+			URLID:         p.URL.ID,
+			Payload:       string(body),
+			NextAttemptAt: &claimedUntil,
+		}
+		if err := models.InsertWebhookDelivery(delivery); err != nil {
+			log.Printf("⚠️ Failed to log webhook delivery to webhook ID %d: %v", w.ID, err)
+			continue
+		}
+		go deliver(w, delivery)
+	}
+}
+
+// buildPayloads returns the payloads of the events raised by a finished crawl:
+// crawl.error for a failure, and crawl.done plus broken_links.found if the
+// completed crawl found broken links.
+func buildPayloads(f crawler.FinishedCrawl) ([]Payload, error) {
+	base, err := basePayload(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Err != nil {
+		base.Event = models.WebhookCrawlError
+		if base.URL.ErrorMessage == "" {
+			base.URL.ErrorMessage = f.Err.Error()
+		}
+		return []Payload{base}, nil
+	}
+
+	counts, err := models.GetLinkCountByURLID(f.URLID)
+	if err != nil {
+		return nil, err
+	}
+	base.LinkCount = counts
+
+	done := base
+	done.Event = models.WebhookCrawlDone
+	payloads := []Payload{done}
+
+	if counts.Broken > 0 {
+		brokenLinks, err := models.GetBrokenLinksByURLID(f.URLID)
+		if err != nil {
+			return nil, err
+		}
+		broken := base
+		broken.Event = models.WebhookBrokenLinksFound
+		broken.BrokenLinks = brokenLinks
+		payloads = append(payloads, broken)
+	}
+	return payloads, nil
+}
+
+// basePayload returns a payload describing a URL and its latest crawl run, without an event.
+func basePayload(urlID int) (Payload, error) {
+	urlObj, err := models.GetURLByID(urlID)
+	if err != nil {
+		return Payload{}, err
+	}
+	run, err := models.GetLatestCrawlRun(urlID)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	return Payload{
+		Timestamp: time.Now().UTC(),
+		URL: PayloadURL{
+			ID:           urlObj.ID,
+			URL:          urlObj.URL,
+			Title:        urlObj.Title,
+			Status:       urlObj.Status,
+			ErrorMessage: urlObj.ErrorMessage,
+		},
+		Run: run,
+	}, nil
+}
+
+// retryPollInterval is how often pending deliveries are checked for a due retry.
+const retryPollInterval = time.Second
+
+// RunRetries retries failed deliveries as they become due until ctx is cancelled.
+// Retries are stored with the delivery, so they survive restarts of any process.
+func RunRetries(ctx context.Context) {
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			retryDueDeliveries(now)
+		}
+	}
+}
+
+// retryDueDeliveries claims the deliveries whose next attempt is due and attempts them.
+func retryDueDeliveries(now time.Time) {
+	deliveries, err := models.GetDueWebhookDeliveries(now, 100)
+	if err != nil {
+		log.Printf("⚠️ Failed to load pending webhook deliveries: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		d := &deliveries[i]
+		claimed, err := models.ClaimWebhookDelivery(d, now.Add(attemptLease()))
+		if err != nil {
+			log.Printf("⚠️ Failed to claim webhook delivery ID %d: %v", d.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		w, err := models.GetWebhookByID(d.WebhookID)
+		if err != nil {
+			log.Printf("⚠️ Failed to load webhook ID %d: %v", d.WebhookID, err)
+			continue
+		}
+		go deliver(w, d)
+	}
+}
+
+// deliver makes one attempt of a claimed delivery. Until the receiver answers with
+// a 2xx status or the configured number of attempts is used up, the next attempt is
+// scheduled with exponential backoff for RunRetries. The outcome of every attempt is
+// saved to the delivery log.
+func deliver(w *models.Webhook, d *models.WebhookDelivery) {
+	secret, err := w.Secret()
+	if err != nil {
+		finishDelivery(d, fmt.Sprintf("failed to decrypt secret: %v", err))
+		return
+	}
+
+	d.Attempts++
+	d.StatusCode, err = send(w, d, secret)
+	switch {
+	case err == nil:
+		d.Success = true
+		finishDelivery(d, "")
+		return
+	case d.Attempts >= max(config.Cfg.WebhookMaxAttempts, 1):
+		finishDelivery(d, err.Error())
+		return
+	}
+
+	next := time.Now().Add(retryDelay(d.Attempts))
+	d.Error = err.Error()
+	d.NextAttemptAt = &next
+	if err := models.UpdateWebhookDelivery(d); err != nil {
+		log.Printf("⚠️ Failed to update webhook delivery ID %d: %v", d.ID, err)
+	}
+}
+
+// send makes one delivery attempt. It returns the response status, if any, and
+// an error unless the receiver accepted the delivery.
+func send(w *models.Webhook, d *models.WebhookDelivery, secret []byte) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "urlcrawler-webhooks")
+	req.Header.Set("X-Webhook-Event", string(d.Event))
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(d.ID))
+	req.Header.Set("X-Webhook-Signature", Sign(secret, body))
+
+	client := &http.Client{
+		Timeout:       time.Duration(config.Cfg.WebhookTimeoutSeconds) * time.Second,
+		CheckRedirect: noRedirect,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// attemptLease is how long a claimed delivery is left to its process before another
+// may attempt it: longer than an attempt can take.
+func attemptLease() time.Duration {
+	return time.Duration(config.Cfg.WebhookTimeoutSeconds)*time.Second + time.Minute
+}
+
+// retryDelay returns the wait before the given retry (1 for the first retry).
+func retryDelay(retry int) time.Duration {
+	const maxDelay = time.Hour
+	delay := time.Duration(config.Cfg.WebhookBaseDelayMs) * time.Millisecond << (retry - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+func finishDelivery(d *models.WebhookDelivery, errMsg string) {
+	now := time.Now()
+	d.Error = errMsg
+	d.CompletedAt = &now
+	d.NextAttemptAt = nil
+	if err := models.UpdateWebhookDelivery(d); err != nil {
+		log.Printf("⚠️ Failed to update webhook delivery ID %d: %v", d.ID, err)
+	}
+	if !d.Success {
+		log.Printf("⚠️ Webhook delivery ID %d to webhook ID %d failed after %d attempts: %s", d.ID, d.WebhookID, d.Attempts, errMsg)
+	}
+}
@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"urlcrawler/internal/auth"
+	"urlcrawler/internal/config"
+	"urlcrawler/internal/db/dbtest"
+	"urlcrawler/internal/models"
+)
+
+// receivedRequest is a delivery attempt as seen by the test receiver.
+type receivedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// receiver is a webhook endpoint answering attempts with the given statuses in turn,
+// repeating the last one.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+		r.requests = append(r.requests, receivedRequest{Header: req.Header.Clone(), Body: body})
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest{}, r.requests...)
+}
+
+// setup prepares the database and configuration and registers a webhook for crawl.done.
+func setup(t *testing.T, endpoint, secret string, maxAttempts int) models.Webhook {
+	dbtest.Open(t, &models.Webhook{}, &models.WebhookDelivery{})
+	if err := auth.SetEncryptionKey("test key"); err != nil {
+		t.Fatal(err)
+	}
+	config.Cfg.WebhookMaxAttempts = maxAttempts
+	config.Cfg.WebhookBaseDelayMs = 1
+	config.Cfg.WebhookTimeoutSeconds = 5
+
+	w := models.Webhook{URL: endpoint, Events: []models.WebhookEvent{models.WebhookCrawlDone}, Active: true, CreatedBy: 1}
+	if err := models.InsertWebhook(&w, secret); err != nil {
+		t.Fatalf("failed to register webhook: %v", err)
+	}
+	return w
+}
+
+// waitForDelivery polls the delivery log until the only delivery satisfies done, and returns it.
+func waitForDelivery(t *testing.T, webhookID int, done func(d models.WebhookDelivery) bool) models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := models.GetWebhookDeliveries(webhookID, 10)
+		if err != nil {
+			t.Fatalf("failed to load deliveries: %v", err)
+		}
+		if len(deliveries) > 1 {
+			t.Fatalf("got %d deliveries, want 1", len(deliveries))
+		}
+		if len(deliveries) == 1 && done(deliveries[0]) {
+			return deliveries[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for delivery: %+v", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func attempted(n int) func(d models.WebhookDelivery) bool {
+	return func(d models.WebhookDelivery) bool { return d.Attempts >= n }
+}
+
+func completed(d models.WebhookDelivery) bool {
+	return d.CompletedAt != nil
+}
+
+func TestDeliveryRetriedAfterServerError(t *testing.T) {
+	recv := newReceiver(t, http.StatusInternalServerError, http.StatusNoContent)
+	w := setup(t, recv.URL, "s3cret", 3)
+
+	dispatch([]models.Webhook{w}, Payload{Event: models.WebhookCrawlDone, URL: PayloadURL{ID: 7}})
+
+	first := waitForDelivery(t, w.ID, attempted(1))
+	if first.CompletedAt != nil || first.Success || first.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("after the failed attempt: %+v", first)
+	}
+	if first.NextAttemptAt == nil || first.Error == "" {
+		t.Fatalf("retry not scheduled: %+v", first)
+	}
+
+	// The retry is made by whichever process polls once it is due
+	retryDueDeliveries(time.Now().Add(time.Second))
+	final := waitForDelivery(t, w.ID, completed)
+	if !final.Success || final.StatusCode != http.StatusNoContent || final.Error != "" || final.NextAttemptAt != nil {
+		t.Errorf("after the successful retry: %+v", final)
+	}
+
+	requests := recv.received()
+	if len(requests) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(requests))
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(requests[1].Body)
+	for i, req := range requests {
+		if got, want := req.Header.Get("X-Webhook-Signature"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("request %d signature = %q, want %q", i, got, want)
+		}
+		if got := req.Header.Get("X-Webhook-Event"); got != string(models.WebhookCrawlDone) {
+			t.Errorf("request %d event = %q", i, got)
+		}
+		if got := req.Header.Get("X-Webhook-Delivery"); got != strconv.Itoa(final.ID) {
+			t.Errorf("request %d delivery ID = %q, want %d", i, got, final.ID)
+		}
+	}
+
+	var p Payload
+	if err := json.Unmarshal(requests[0].Body, &p); err != nil || p.Event != models.WebhookCrawlDone || p.URL.ID != 7 {
+		t.Errorf("payload = %s (%v)", requests[0].Body, err)
+	}
+}
+
+func TestDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	recv := newReceiver(t, http.StatusBadGateway)
+	w := setup(t, recv.URL, "s3cret", 2)
+
+	dispatch([]models.Webhook{w}, Payload{Event: models.WebhookCrawlDone, URL: PayloadURL{ID: 7}})
+	waitForDelivery(t, w.ID, attempted(1))
+	retryDueDeliveries(time.Now().Add(time.Second))
+	final := waitForDelivery(t, w.ID, completed)
+
+	if final.Success || final.StatusCode != http.StatusBadGateway || final.Error == "" || final.NextAttemptAt != nil {
+		t.Errorf("after the last attempt: %+v", final)
+	}
+	// A completed delivery is never attempted again
+	retryDueDeliveries(time.Now().Add(time.Hour))
+	time.Sleep(50 * time.Millisecond)
+	if n := len(recv.received()); n != 2 {
+		t.Errorf("receiver got %d requests, want 2", n)
+	}
+}
+
+func TestDeliveryNotSubscribed(t *testing.T) {
+	recv := newReceiver(t, http.StatusOK)
+	w := setup(t, recv.URL, "s3cret", 1)
+
+	dispatch([]models.Webhook{w}, Payload{Event: models.WebhookCrawlError, URL: PayloadURL{ID: 7}})
+
+	deliveries, err := models.GetWebhookDeliveries(w.ID, 10)
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("deliveries = %+v (%v), want none", deliveries, err)
+	}
+}
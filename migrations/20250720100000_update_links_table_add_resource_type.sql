@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+    ADD COLUMN resource_type VARCHAR(20) NOT NULL DEFAULT 'link';
+
+-- +goose Down
+ALTER TABLE links
+    DROP COLUMN resource_type;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE mixed_content_issues (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL,
+    href TEXT NOT NULL,
+    element VARCHAR(20) NOT NULL,
+    kind ENUM('active', 'passive', 'form', 'downgrade') NOT NULL,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+ALTER TABLE urls
+    ADD COLUMN mixed_content_count INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE urls
+    DROP COLUMN mixed_content_count;
+
+DROP TABLE IF EXISTS mixed_content_issues;
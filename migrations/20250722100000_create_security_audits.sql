@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE security_audits (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL UNIQUE,
+    headers TEXT,
+    checks TEXT,
+    grade ENUM('pass', 'warn', 'fail') NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS security_audits;
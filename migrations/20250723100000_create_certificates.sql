@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE certificates (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL UNIQUE,
+    tls_version VARCHAR(20),
+    cipher_suite VARCHAR(100),
+    subject VARCHAR(512),
+    issuer VARCHAR(512),
+    not_before TIMESTAMP NULL,
+    not_after TIMESTAMP NULL,
+    hostname_match BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_soon BOOLEAN NOT NULL DEFAULT FALSE,
+    verify_error TEXT,
+    chain TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS certificates;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE crawl_runs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL,
+    status ENUM('running', 'done', 'error', 'stopped') DEFAULT 'running',
+    error_message TEXT,
+    dns_ms BIGINT DEFAULT 0,
+    connect_ms BIGINT DEFAULT 0,
+    tls_ms BIGINT DEFAULT 0,
+    ttfb_ms BIGINT DEFAULT 0,
+    download_ms BIGINT DEFAULT 0,
+    total_ms BIGINT DEFAULT 0,
+    transfer_size BIGINT DEFAULT 0,
+    content_size BIGINT DEFAULT 0,
+    resource_count INT DEFAULT 0,
+    page_weight BIGINT DEFAULT 0,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP NULL,
+    INDEX idx_crawl_runs_url_started (url_id, started_at),
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_runs;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+    ADD COLUMN issue VARCHAR(30) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE links
+    DROP COLUMN issue;
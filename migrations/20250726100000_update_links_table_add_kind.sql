@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'http';
+
+-- +goose Down
+ALTER TABLE links
+    DROP COLUMN kind;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE links
+    ADD COLUMN check_method VARCHAR(10) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE links
+    DROP COLUMN check_method;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE links
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN is_flaky BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE links
+    DROP COLUMN attempts,
+    DROP COLUMN is_flaky;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE crawl_settings (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL UNIQUE,
+    encrypted_credentials TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_settings;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE crawl_runs
+    MODIFY status ENUM('running', 'done', 'error', 'stopped', 'login_failed') DEFAULT 'running';
+
+-- +goose Down
+ALTER TABLE crawl_runs
+    MODIFY status ENUM('running', 'done', 'error', 'stopped') DEFAULT 'running';
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE page_snapshots (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    run_id INT NOT NULL UNIQUE,
+    url_id INT NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    html MEDIUMBLOB NOT NULL,
+    size INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (run_id) REFERENCES crawl_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+ALTER TABLE crawl_runs
+    ADD COLUMN content_hash CHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN content_changed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN analysis_skipped BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE urls
+    ADD COLUMN volatile_selectors TEXT;
+
+-- +goose Down
+ALTER TABLE urls
+    DROP COLUMN volatile_selectors;
+
+ALTER TABLE crawl_runs
+    DROP COLUMN content_hash,
+    DROP COLUMN content_changed,
+    DROP COLUMN analysis_skipped;
+
+DROP TABLE IF EXISTS page_snapshots;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE urls
+    ADD COLUMN etag VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN last_modified VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE crawl_runs
+    ADD COLUMN not_modified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE crawl_runs
+    DROP COLUMN not_modified;
+
+ALTER TABLE urls
+    DROP COLUMN etag,
+    DROP COLUMN last_modified;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE crawl_runs
+    ADD COLUMN warc_path VARCHAR(512) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE crawl_runs
+    DROP COLUMN warc_path;
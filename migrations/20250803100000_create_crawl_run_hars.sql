@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE crawl_run_hars (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    run_id INT NOT NULL UNIQUE,
+    url_id INT NOT NULL,
+    har MEDIUMBLOB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (run_id) REFERENCES crawl_runs(id) ON DELETE CASCADE,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_run_hars;
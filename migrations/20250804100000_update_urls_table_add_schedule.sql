@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE urls
+    ADD COLUMN schedule VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN next_run_at DATETIME NULL,
+    ADD COLUMN last_run_at DATETIME NULL,
+    ADD INDEX idx_urls_next_run_at (next_run_at);
+
+-- +goose Down
+ALTER TABLE urls
+    DROP INDEX idx_urls_next_run_at,
+    DROP COLUMN schedule,
+    DROP COLUMN next_run_at,
+    DROP COLUMN last_run_at;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE crawl_checkpoints (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL UNIQUE,
+    run_id INT NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    etag VARCHAR(255) NOT NULL DEFAULT '',
+    last_modified VARCHAR(64) NOT NULL DEFAULT '',
+    pending MEDIUMTEXT NOT NULL,
+    checked INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    FOREIGN KEY (run_id) REFERENCES crawl_runs(id) ON DELETE CASCADE
+);
+
+ALTER TABLE crawl_runs
+    MODIFY status ENUM('running', 'done', 'error', 'stopped', 'login_failed', 'paused') DEFAULT 'running';
+
+ALTER TABLE urls
+    MODIFY status ENUM('queued', 'processing', 'done', 'error', 'stopped', 'paused') DEFAULT 'queued';
+
+-- +goose Down
+ALTER TABLE urls
+    MODIFY status ENUM('queued', 'processing', 'done', 'error') DEFAULT 'queued';
+
+ALTER TABLE crawl_runs
+    MODIFY status ENUM('running', 'done', 'error', 'stopped', 'login_failed') DEFAULT 'running';
+
+DROP TABLE IF EXISTS crawl_checkpoints;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE email_subscriptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    url_id INT NOT NULL,
+    frequency ENUM('immediate', 'daily', 'weekly') NOT NULL DEFAULT 'immediate',
+    next_digest_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY idx_email_subscription (user_id, url_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+CREATE TABLE digest_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    subject VARCHAR(512) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_digest_items_subscription_id (subscription_id),
+    FOREIGN KEY (subscription_id) REFERENCES email_subscriptions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE broken_link_snapshots (
+    url_id INT PRIMARY KEY,
+    hrefs MEDIUMTEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS broken_link_snapshots;
+DROP TABLE IF EXISTS digest_items;
+DROP TABLE IF EXISTS email_subscriptions;
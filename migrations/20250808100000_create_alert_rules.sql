@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE alert_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL,
+    kind ENUM('broken_links', 'status_error', 'title_changed', 'response_time', 'cert_expiry') NOT NULL,
+    threshold INT NOT NULL DEFAULT 0,
+    state ENUM('firing', 'resolved') NOT NULL DEFAULT 'resolved',
+    value TEXT,
+    message TEXT,
+    last_evaluated_at DATETIME NULL,
+    changed_at DATETIME NULL,
+    created_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_alert_rules_url_id (url_id),
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_rules;
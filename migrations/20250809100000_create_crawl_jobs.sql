@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE crawl_jobs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    priority INT NOT NULL DEFAULT 1,
+    options TEXT NOT NULL,
+    control ENUM('', 'stop', 'pause') NOT NULL DEFAULT '',
+    worker_id VARCHAR(255) NOT NULL DEFAULT '',
+    lease_expires_at DATETIME NULL,
+    heartbeat_at DATETIME NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    queued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_crawl_jobs_lease (control, worker_id, lease_expires_at),
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_jobs;
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE webhook_deliveries
+    ADD COLUMN next_attempt_at DATETIME NULL,
+    ADD INDEX idx_webhook_deliveries_next_attempt_at (next_attempt_at);
+
+-- Deliveries left pending by a restart are retried
+UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP WHERE completed_at IS NULL;
+
+-- +goose Down
+ALTER TABLE webhook_deliveries
+    DROP INDEX idx_webhook_deliveries_next_attempt_at,
+    DROP COLUMN next_attempt_at;
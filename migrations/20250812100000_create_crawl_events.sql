@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE crawl_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    url_id INT NOT NULL,
+    event TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_crawl_events_created_at (created_at)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS crawl_events;